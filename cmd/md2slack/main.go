@@ -6,11 +6,14 @@ import (
 	"md2slack/internal/config"
 	"md2slack/internal/gitdiff"
 	"md2slack/internal/llm"
+	"md2slack/internal/notify"
+	"md2slack/internal/renderer"
 	"md2slack/internal/storage"
 	"md2slack/internal/webui"
 	"net"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -20,11 +23,18 @@ func main() {
 	var debug bool
 	var install bool
 	var webAddr string
+	var format string
 	flag.BoolVar(&debug, "debug", false, "Enable debug mode")
 	flag.BoolVar(&install, "install", false, "Install the binary")
 	flag.StringVar(&webAddr, "web-addr", "127.0.0.1:8080", "Web UI address")
+	flag.StringVar(&format, "format", "", "Print the final report as "+strings.Join(renderer.FormatNames(), ", "))
 	flag.Parse()
 
+	if _, ok := renderer.LookupFormat(format); !ok {
+		fmt.Fprintf(os.Stderr, "Unknown format %q (available: %s)\n", format, strings.Join(renderer.FormatNames(), ", "))
+		os.Exit(1)
+	}
+
 	if install {
 		runInstall()
 		return
@@ -54,6 +64,26 @@ func main() {
 		os.Exit(1)
 	}
 
+	gitBackend, err := gitdiff.NewBackend(cfg.Git.Backend)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	gitdiff.SetBackend(gitBackend)
+	gitdiff.SetDiffLimits(gitdiff.DiffLimits{
+		Excludes:       cfg.Diff.Exclude,
+		MaxFileLines:   cfg.Diff.MaxFileLines,
+		MaxCommitLines: cfg.Diff.MaxCommitLines,
+	})
+	gitdiff.SetDetectorSettings(gitdiff.DetectorSettings{
+		Auto:        cfg.Detectors.Auto,
+		Disable:     cfg.Detectors.Disable,
+		Enable:      cfg.Detectors.Enable,
+		RepoDisable: cfg.Detectors.RepoDisable,
+		RepoEnable:  cfg.Detectors.RepoEnable,
+		Weights:     cfg.Detectors.Weights,
+	})
+
 	flagWebAddr := flag.Lookup("web-addr")
 	webAddrDefault := "127.0.0.1:8080"
 	if flagWebAddr != nil {
@@ -83,6 +113,19 @@ func main() {
 
 	// Always start web server
 	webServer := webui.Start(webAddr, stageNames)
+	webServer.SetReportTemplate(func(repoName string) string {
+		return cfg.Report.TemplateFor(repoName, "")
+	})
+	webServer.SetDestinationsHandler(func(repoName string) ([]string, []string) {
+		available := notify.Names(notify.Configured(cfg, repoName, ""))
+		var selected []string
+		for _, dest := range cfg.Destinations.For(repoName) {
+			if slices.Contains(available, dest) {
+				selected = append(selected, dest)
+			}
+		}
+		return available, selected
+	})
 
 	processor := &ReportProcessor{
 		Config: cfg,
@@ -100,6 +143,7 @@ func main() {
 		WebServer:  webServer,
 		Debug:      debug,
 		StageNames: stageNames,
+		Format:     format,
 	}
 
 	if len(dates) == 0 {
@@ -111,6 +155,7 @@ func main() {
 				if err != nil {
 					return nil, "", err
 				}
+				processor.LoadPrivacy(repo, date)
 				if hist == nil {
 					return nil, "", nil
 				}
@@ -122,10 +167,21 @@ func main() {
 			},
 		)
 
+		webServer.SetBlockerHandlers(
+			func(repoName string, date string) ([]gitdiff.Blocker, error) {
+				return storage.LoadOpenBlockers(repoName, date)
+			},
+			func(repoName string, date string, blockers []gitdiff.Blocker) ([]gitdiff.Blocker, error) {
+				return storage.SaveBlockers(repoName, blockers)
+			},
+		)
+
 		// Register action handlers immediately so they're available before any analysis runs
 		webServer.SetActionHandler(
 			func(action string, selected []int, tasks []gitdiff.TaskChange) ([]gitdiff.TaskChange, error) {
-				return llm.EditTasksWithAction(tasks, action, selected, processor.LLMOpts)
+				opts := processor.LLMOpts
+				opts.Privacy = processor.PrivacyFor(webServer.GetRepo())
+				return llm.EditTasksWithAction(tasks, action, selected, opts)
 			},
 			func(index int, task gitdiff.TaskChange, tasks []gitdiff.TaskChange) ([]gitdiff.TaskChange, error) {
 				if index < 0 || index >= len(tasks) {
@@ -138,19 +194,19 @@ func main() {
 
 		// Register chat handler with callbacks for streaming tool events
 		webServer.SetChatWithCallbacks(
-			func(history []webui.OpenAIMessage, tasks []gitdiff.TaskChange, callbacks webui.ChatCallbacks) ([]gitdiff.TaskChange, string, error) {
+			func(history []webui.OpenAIMessage, tasks []gitdiff.TaskChange, blockers []gitdiff.Blocker, date string, callbacks webui.ChatCallbacks) ([]gitdiff.TaskChange, []gitdiff.Blocker, string, error) {
 				var llmHistory []llm.OpenAIMessage
 				for _, msg := range history {
 					llmHistory = append(llmHistory, llm.OpenAIMessage{Role: msg.Role, Content: msg.Content})
 				}
 				// Create LLM options with callbacks
 				opts := processor.LLMOpts
+				opts.Privacy = processor.PrivacyFor(webServer.GetRepo())
 				opts.OnToolStart = callbacks.OnToolStart
 				opts.OnToolEnd = callbacks.OnToolEnd
 				opts.OnStreamChunk = callbacks.OnStreamChunk
 
-				updated, text, err := llm.StreamChatWithRequests(llmHistory, tasks, opts, nil)
-				return updated, text, err
+				return llm.StreamChatWithRequests(llmHistory, tasks, blockers, date, opts, nil)
 			},
 		)
 
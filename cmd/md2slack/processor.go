@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"md2slack/internal/config"
 	"md2slack/internal/gitdiff"
+	"md2slack/internal/issues"
 	"md2slack/internal/llm"
+	"md2slack/internal/notify"
 	"md2slack/internal/renderer"
 	"md2slack/internal/slack"
 	"md2slack/internal/storage"
 	"md2slack/internal/webui"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,6 +25,12 @@ type ReportProcessor struct {
 	WebServer  *webui.Server
 	Debug      bool
 	StageNames []string
+	Format     string
+
+	// privacy holds each repo's settings for the web UI's chat and task
+	// actions, from its last run or from history loaded for it.
+	privacyMu sync.Mutex
+	privacy   map[string]*llm.Privacy
 }
 
 func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverride string, extraContext string) {
@@ -46,6 +56,10 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 		p.WebServer.Reset(p.StageNames, date, repoName)
 		ui = p.WebServer
 
+		if blockers, err := storage.LoadOpenBlockers(repoName, date); err == nil {
+			p.WebServer.SetBlockers(blockers)
+		}
+
 		// Re-load previous session if it exists
 		if hist, err := storage.LoadHistory(repoName, date); err == nil && hist != nil {
 			p.WebServer.SetTasks(hist.Tasks, nil)
@@ -91,11 +105,24 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 		fmt.Println(msg)
 	}
 
+	// Blockers given on the command line are recorded directly instead of
+	// being handed to the LLM as task context.
+	newBlockers, extraContext := gitdiff.ParseBlockers(extraContext, date)
+	if len(newBlockers) > 0 {
+		if _, err := storage.SaveBlockers(repoName, newBlockers); err != nil {
+			errf("Warning: failed to save blockers: %v", err)
+		}
+	}
+
 	// --- STAGE 0: Preparing commit context ---
 	stageStart := time.Now()
 	if ui != nil {
 		ui.StageStart(0, "")
 	}
+	// Detector files are re-read on every run so edits need no restart
+	if err := gitdiff.LoadUserDetectors(); err != nil {
+		errf("Warning: skipped invalid detectors: %v", err)
+	}
 	output, err := gitdiff.GenerateFactsWithOptions(date, extraContext, repoPath, authorOverride)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error generating facts for %s: %v\n", date, err)
@@ -104,8 +131,28 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 		}
 		return
 	}
+	sessionMinutes := p.annotateSessionTime(output)
+	commitIssues := p.annotateIssues(output, errf)
+	output.Activity = p.collectActivity(date, repoPath, authorOverride, errf)
+	workInProgress := p.collectWorkInProgress(date, repoPath, errf)
+	secrets := output.Secrets
+	for _, w := range workInProgress {
+		secrets = append(secrets, w.Secrets...)
+	}
+	for _, s := range secrets {
+		errf("Warning: possible secret committed in %s (%s, %s); it was masked before analysis", s.Commit, s.Where(), s.Kind)
+	}
+	localLLMOpts.Privacy = p.privacyFor(repoName, output.Commits, workInProgress, errf)
+	p.setPrivacy(repoName, localLLMOpts.Privacy)
 	if ui != nil {
-		ui.StageDone(0, fmt.Sprintf("%d commits found", len(output.Commits)))
+		stageMsg := fmt.Sprintf("%d commits found", len(output.Commits))
+		if len(output.Activity) > 0 {
+			stageMsg += fmt.Sprintf(", %d merges/reviews", len(output.Activity))
+		}
+		if len(workInProgress) > 0 {
+			stageMsg += fmt.Sprintf(", %d uncommitted", len(workInProgress))
+		}
+		ui.StageDone(0, stageMsg)
 	}
 	logf("Stage 0 done in %s", time.Since(stageStart).Truncate(time.Millisecond))
 
@@ -135,7 +182,7 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 			cc, err := llm.ExtractCommitIntent(gitdiff.SemanticChange{
 				CommitHash: c.Hash,
 				Signals:    semantic.Signals,
-			}, c.Message, localLLMOpts)
+			}, c.FullMessage(), localLLMOpts)
 			if err != nil {
 				results <- commitResult{index: idx, err: err}
 				return
@@ -152,6 +199,30 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 			continue
 		}
 		commitChanges[res.index] = *res.cc
+		commitChanges[res.index].SessionMinutes = sessionMinutes[output.Commits[res.index].Hash]
+		refs := commitIssues[output.Commits[res.index].Hash]
+		commitChanges[res.index].IssueKeys = gitdiff.IssueKeys(refs)
+		commitChanges[res.index].Issues = gitdiff.EnrichedIssues(refs)
+		for _, sem := range output.Semantic {
+			if sem.CommitHash == output.Commits[res.index].Hash {
+				commitChanges[res.index].Branch = sem.Branch
+				break
+			}
+		}
+	}
+
+	// Uncommitted work goes through the same intent extraction; a failure
+	// only loses the wording, the task is still described from its files.
+	var wipTasks []gitdiff.TaskChange
+	for _, w := range workInProgress {
+		cc, err := llm.ExtractCommitIntent(gitdiff.SemanticChange{
+			CommitHash: w.Ref,
+			Signals:    w.Semantic.Signals,
+		}, w.Message, localLLMOpts)
+		if err != nil {
+			errf("Error analyzing %s: %v", w.Ref, err)
+		}
+		wipTasks = append(wipTasks, gitdiff.WorkInProgressTask(w, cc, localLLMOpts.Privacy.AllowsCode()))
 	}
 
 	if ui != nil {
@@ -173,6 +244,10 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 
 	manualTasks, _ := llm.IncorporateExtraContext(output.Extra, localLLMOpts)
 
+	// Commits on the same feature branch are pre-grouped deterministically;
+	// the LLM then details the seeded tasks and places the remaining commits.
+	allTasks = gitdiff.SeedBranchTasks(allTasks, output.Semantic)
+
 	allowedCommits := make(map[string]struct{})
 	for _, c := range output.Commits {
 		allowedCommits[c.Hash] = struct{}{}
@@ -189,6 +264,9 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 			continue
 		}
 		allTasks = updated
+		if commitIssues != nil {
+			allTasks = gitdiff.ApplyIssues(allTasks, commitIssues)
+		}
 	}
 
 	// Merge new manual tasks if any
@@ -209,6 +287,21 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 	if err != nil {
 		errf("Warning: task review failed: %v", err)
 	}
+	if sessionMinutes != nil {
+		allTasks = gitdiff.ApplySessionEstimates(allTasks, sessionMinutes, p.Config.Estimation.Mode == "direct")
+	}
+	if commitIssues != nil {
+		allTasks = gitdiff.ApplyIssues(allTasks, commitIssues)
+	}
+	allTasks = gitdiff.ApplyUntested(allTasks, output.Semantic)
+	// Merges and reviews have no diff for the LLM to work from, so they are
+	// added as-is after the review.
+	allTasks = gitdiff.AppendActivityTasks(allTasks, output.Activity)
+	// Re-running today replaces the earlier in-progress tasks, so work
+	// committed or discarded since then drops out.
+	if p.readsWorkingTree(date) {
+		allTasks = gitdiff.AppendWorkInProgressTasks(allTasks, wipTasks)
+	}
 	if ui != nil {
 		ui.StageDone(3, "Refined")
 	}
@@ -233,17 +326,34 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 	if ui != nil {
 		ui.StageStart(5, "")
 	}
-	report := renderer.RenderReport(date, nil, allTasks, nextActions)
+	blockers, err := storage.LoadOpenBlockers(repoName, date)
+	if err != nil {
+		errf("Warning: failed to load blockers: %v", err)
+	}
+	reportData := renderer.Report{
+		Date:        date,
+		Repo:        repoName,
+		Tasks:       allTasks,
+		NextActions: nextActions,
+		Blockers:    blockers,
+		Secrets:     secrets,
+	}
+	report := renderer.RenderWith(p.Config.Report.TemplateFor(repoName, ""), reportData)
 	if p.WebServer != nil {
+		p.WebServer.SetSecrets(secrets)
+		p.WebServer.SetBlockers(blockers)
 		p.WebServer.SetTasks(allTasks, nextActions)
 		p.WebServer.SetReport(report)
 		p.WebServer.SetHandlers(
-			func(report string) error {
+			func(channel string, report string) error {
 				if p.Debug {
-					logf("Debug: send requested; skipping Slack send")
+					logf("Debug: send requested; skipping %s send", channel)
 					return nil
 				}
-				return slack.SendMarkdown(&p.Config.Slack, report)
+				if name := p.Config.Report.TemplateFor(repoName, channel); name != p.Config.Report.TemplateFor(repoName, "") {
+					report = renderer.RenderWith(name, p.WebServer.ReportData())
+				}
+				return notify.Send(notify.Configured(p.Config, repoName, date), channel, reportSubject(date, repoName), report)
 			},
 			func(prompt string, tasks []gitdiff.TaskChange) ([]gitdiff.TaskChange, error) {
 				return llm.RefineTasksWithPrompt(tasks, prompt, localLLMOpts)
@@ -263,7 +373,17 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 	}
 	logf("Stage 5 done in %s", time.Since(stageStart).Truncate(time.Millisecond))
 	fmt.Println("\n--- FINAL REPORT ---")
-	fmt.Println(report)
+	if p.Format != "" {
+		formatted, err := renderer.RenderFormat(p.Format, report, reportData)
+		if err != nil {
+			errf("Warning: failed to render %s output: %v", p.Format, err)
+			fmt.Println(report)
+		} else {
+			fmt.Println(formatted)
+		}
+	} else {
+		fmt.Println(report)
+	}
 
 	// Save History
 	if err := storage.SaveHistory(repoName, date, allTasks, nil, nil, report); err != nil {
@@ -282,14 +402,207 @@ func (p *ReportProcessor) ProcessDate(date string, repoPath string, authorOverri
 		b, _ := json.MarshalIndent(blocks, "", "  ")
 		fmt.Println(string(b))
 	} else if p.WebServer == nil {
-		fmt.Println("Sending to Slack...")
-		if err := slack.SendMarkdown(&p.Config.Slack, report); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending to Slack for %s: %v\n", date, err)
-			return
+		notifiers := notify.Configured(p.Config, repoName, date)
+		for _, dest := range p.Config.Destinations.For(repoName) {
+			fmt.Printf("Sending to %s...\n", dest)
+			out := renderer.RenderWith(p.Config.Report.TemplateFor(repoName, dest), reportData)
+			if err := notify.Send(notifiers, dest, reportSubject(date, repoName), out); err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending to %s for %s: %v\n", dest, date, err)
+				continue
+			}
+			fmt.Printf("Daily Status Report for %s sent to %s!\n", date, dest)
 		}
-		fmt.Printf("Daily Status Report for %s sent successfully!\n", date)
 	} else {
 		fmt.Println("Web UI enabled: report ready; use the Send button to post to Slack.")
 	}
 	logf("Total elapsed: %s", time.Since(runStart).Truncate(time.Millisecond))
 }
+
+// annotateSessionTime clusters the day's commits into work sessions and
+// records the minutes attributed to each commit on its semantic entry. It
+// returns nil when estimation is off.
+func (p *ReportProcessor) annotateSessionTime(output *gitdiff.Output) map[string]int {
+	est := p.Config.Estimation
+	if est.Mode == "off" {
+		return nil
+	}
+	minutes := gitdiff.SessionMinutes(output.Commits, gitdiff.SessionOptions{
+		IdleGap: time.Duration(est.IdleGapMinutes) * time.Minute,
+		LeadIn:  time.Duration(est.LeadInMinutes) * time.Minute,
+	})
+	for i := range output.Semantic {
+		output.Semantic[i].SessionMinutes = minutes[output.Semantic[i].CommitHash]
+	}
+	return minutes
+}
+
+// annotateIssues extracts issue keys from each commit with the configured
+// trackers, fetches their titles and statuses from trackers with enrichment
+// enabled, and records the keys on the commit's semantic entry. Trackers that
+// fail to compile are reported and skipped.
+func (p *ReportProcessor) annotateIssues(output *gitdiff.Output, errf func(string, ...interface{})) map[string][]gitdiff.IssueRef {
+	var trackers []gitdiff.IssueTracker
+	for _, ic := range p.Config.Issues {
+		tracker, err := gitdiff.NewIssueTracker(ic.Name, ic.Pattern, ic.URL, ic.Projects)
+		if err != nil {
+			errf("Warning: %v", err)
+			continue
+		}
+		trackers = append(trackers, tracker)
+	}
+	if len(trackers) == 0 {
+		return nil
+	}
+	byCommit := make(map[string][]gitdiff.IssueRef)
+	for _, c := range output.Commits {
+		if refs := gitdiff.ExtractIssues(c, trackers); len(refs) > 0 {
+			byCommit[c.Hash] = refs
+		}
+	}
+	if clients := issues.Configured(p.Config); len(clients) > 0 {
+		enricher := issues.NewEnricher(clients)
+		for hash, refs := range byCommit {
+			enriched, err := enricher.Enrich(refs)
+			if err != nil {
+				errf("Warning: issue enrichment failed: %v", err)
+			}
+			byCommit[hash] = enriched
+		}
+	}
+	for i := range output.Semantic {
+		output.Semantic[i].IssueKeys = gitdiff.IssueKeys(byCommit[output.Semantic[i].CommitHash])
+	}
+	return byCommit
+}
+
+// collectActivity gathers the merges and code reviews enabled under
+// [sources]. Failures are reported and leave that source out.
+func (p *ReportProcessor) collectActivity(date string, repoPath string, authorOverride string, errf func(string, ...interface{})) []gitdiff.Activity {
+	var activity []gitdiff.Activity
+	if p.Config.Sources.Merges {
+		merges, err := gitdiff.MergeActivity(date, repoPath, authorOverride)
+		if err != nil {
+			errf("Warning: failed to read merges: %v", err)
+		}
+		activity = append(activity, merges...)
+	}
+	if gh := issues.MergeSource(p.Config); gh != nil {
+		// Pull requests merged with a merge commit were found locally too.
+		day, err := time.ParseInLocation("2006-01-02", gitdiff.ISODate(date), time.Local)
+		if err == nil {
+			var merged []gitdiff.Activity
+			merged, err = gh.Merges(p.Config.Sources.Reviewer, day)
+			for _, m := range merged {
+				if !slices.ContainsFunc(activity, func(a gitdiff.Activity) bool { return a.Ref == m.Ref }) {
+					activity = append(activity, m)
+				}
+			}
+		}
+		if err != nil {
+			errf("Warning: failed to read merged pull requests: %v", err)
+		}
+	}
+	if gh := issues.ReviewSource(p.Config); gh != nil {
+		day, err := time.ParseInLocation("2006-01-02", gitdiff.ISODate(date), time.Local)
+		if err == nil {
+			var reviews []gitdiff.Activity
+			reviews, err = gh.Reviews(p.Config.Sources.Reviewer, day)
+			activity = append(activity, reviews...)
+		}
+		if err != nil {
+			errf("Warning: failed to read reviews: %v", err)
+		}
+	} else if p.Config.Sources.Reviews {
+		errf("Warning: reviews need an [issues.github] section; skipping them")
+	}
+	return activity
+}
+
+// collectWorkInProgress reads uncommitted changes and recent stashes when
+// the working tree source is enabled. The working tree only reflects the
+// present, so it is skipped for any day but today.
+func (p *ReportProcessor) collectWorkInProgress(date string, repoPath string, errf func(string, ...interface{})) []gitdiff.WorkInProgress {
+	if !p.readsWorkingTree(date) {
+		return nil
+	}
+	since := time.Now().AddDate(0, 0, -p.Config.Sources.StashDays)
+	wip, err := gitdiff.GetWorkInProgress(repoPath, since)
+	if err != nil {
+		errf("Warning: failed to read uncommitted work: %v", err)
+	}
+	return wip
+}
+
+// privacyFor returns the privacy settings of a run over a repo, with an
+// alias for every path of its commits and uncommitted work.
+func (p *ReportProcessor) privacyFor(repoName string, commits []gitdiff.Commit, workInProgress []gitdiff.WorkInProgress, errf func(string, ...interface{})) *llm.Privacy {
+	level, err := llm.ParsePrivacyLevel(p.Config.Privacy.LevelFor(repoName))
+	if err != nil {
+		errf("Warning: %v; sending %s only", err, level)
+	}
+	privacy := llm.NewPrivacy(level, p.Config.Privacy.AnonymizePaths)
+	for _, c := range commits {
+		privacy.AddFiles(c.Files)
+	}
+	for _, w := range workInProgress {
+		privacy.AddFiles(w.Files)
+	}
+	return privacy
+}
+
+func (p *ReportProcessor) setPrivacy(repoName string, privacy *llm.Privacy) {
+	p.privacyMu.Lock()
+	defer p.privacyMu.Unlock()
+	if p.privacy == nil {
+		p.privacy = make(map[string]*llm.Privacy)
+	}
+	p.privacy[repoName] = privacy
+}
+
+// LoadPrivacy sets a repo's privacy settings for history loaded into the web
+// UI, with an alias for every path its commits on date and its uncommitted
+// work touched.
+func (p *ReportProcessor) LoadPrivacy(repoPath string, date string) {
+	repoName := gitdiff.GetRepoNameAt(repoPath)
+	errf := func(format string, args ...interface{}) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+	var commits []gitdiff.Commit
+	var workInProgress []gitdiff.WorkInProgress
+	if p.Config.Privacy.AnonymizePaths {
+		day, err := time.ParseInLocation("2006-01-02", gitdiff.ISODate(date), time.Local)
+		if err == nil {
+			commits, err = gitdiff.CurrentBackend().Commits(repoPath, gitdiff.CommitQuery{
+				Since: day,
+				Until: day.Add(24*time.Hour - time.Second),
+			})
+		}
+		if err != nil {
+			errf("Warning: failed to read commits to anonymise paths: %v", err)
+		}
+		workInProgress = p.collectWorkInProgress(date, repoPath, errf)
+	}
+	p.setPrivacy(repoName, p.privacyFor(repoName, commits, workInProgress, errf))
+}
+
+// PrivacyFor returns the privacy settings of the repo on screen in the web
+// UI. Before a run or loaded history there are no paths to alias, but the
+// repo's level still applies.
+func (p *ReportProcessor) PrivacyFor(repoName string) *llm.Privacy {
+	p.privacyMu.Lock()
+	privacy := p.privacy[repoName]
+	p.privacyMu.Unlock()
+	if privacy != nil {
+		return privacy
+	}
+	level, _ := llm.ParsePrivacyLevel(p.Config.Privacy.LevelFor(repoName))
+	return llm.NewPrivacy(level, p.Config.Privacy.AnonymizePaths)
+}
+
+func (p *ReportProcessor) readsWorkingTree(date string) bool {
+	return p.Config.Sources.WorkingTree && gitdiff.ISODate(date) == time.Now().Format("2006-01-02")
+}
+
+func reportSubject(date string, repoName string) string {
+	return fmt.Sprintf("Daily Status Report %s (%s)", date, repoName)
+}
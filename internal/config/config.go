@@ -31,10 +31,180 @@ type ServerConfig struct {
 	AutoIncrementPort bool
 }
 
+// ReportConfig selects the report template. Per-destination entries win over
+// per-repo entries, which win over the default.
+type ReportConfig struct {
+	Template     string
+	Repos        map[string]string
+	Destinations map[string]string
+}
+
+// TemplateFor returns the template name to use for a repo and destination.
+// Either argument may be empty.
+func (r ReportConfig) TemplateFor(repo string, destination string) string {
+	if name, ok := r.Destinations[strings.ToLower(destination)]; ok && name != "" {
+		return name
+	}
+	if name, ok := r.Repos[repo]; ok && name != "" {
+		return name
+	}
+	if r.Template == "" {
+		return "default"
+	}
+	return r.Template
+}
+
+// EmailConfig holds SMTP settings. Security is "starttls", "tls" (implicit
+// TLS, usually port 465) or "none".
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	Security string
+	To       []string
+	Repos    map[string][]string
+}
+
+// RecipientsFor returns the recipients for a repo, falling back to To.
+func (e EmailConfig) RecipientsFor(repo string) []string {
+	if to, ok := e.Repos[repo]; ok && len(to) > 0 {
+		return to
+	}
+	return e.To
+}
+
+// WebhookConfig holds an incoming webhook URL, used by Teams and Discord.
+type WebhookConfig struct {
+	WebhookURL string
+}
+
+// DestinationsConfig selects where reports are sent by default.
+type DestinationsConfig struct {
+	Default []string
+	Repos   map[string][]string
+}
+
+// For returns the destinations for a repo, falling back to Default and then
+// to Slack.
+func (d DestinationsConfig) For(repo string) []string {
+	if dests, ok := d.Repos[repo]; ok && len(dests) > 0 {
+		return dests
+	}
+	if len(d.Default) > 0 {
+		return d.Default
+	}
+	return []string{"slack"}
+}
+
+// GitConfig selects how repositories are read: "exec" runs the git binary,
+// "go-git" reads them in-process.
+type GitConfig struct {
+	Backend string
+}
+
+// DiffConfig trims the diffs read from every repository. Exclude holds extra
+// .gitignore-style patterns, on top of the built-in ones and each repo's
+// .md2slackignore. The line caps count changed lines; 0 disables a cap.
+type DiffConfig struct {
+	Exclude        []string
+	MaxFileLines   int
+	MaxCommitLines int
+}
+
+// DetectorsConfig chooses the hint detectors run on changed lines. With
+// Auto, framework detectors run only in repos whose manifests use the
+// framework. Disable and Enable apply to every repo, RepoDisable and
+// RepoEnable to one; Disable wins. Weights override a detector's
+// confidence, from 0 to 1.
+type DetectorsConfig struct {
+	Auto        bool
+	Disable     []string
+	Enable      []string
+	RepoDisable map[string][]string
+	RepoEnable  map[string][]string
+	Weights     map[string]float64
+}
+
+// PrivacyConfig limits what is sent to the LLM about a repository. Level is
+// "full" (diffs), "signals" (signal summaries and commit messages) or
+// "metadata" (commit messages and file-type counts); Repos overrides it per
+// repo. With AnonymizePaths, file paths are replaced by aliases in prompts
+// and restored in the report.
+type PrivacyConfig struct {
+	Level          string
+	Repos          map[string]string
+	AnonymizePaths bool
+}
+
+// LevelFor returns the privacy level for a repo, falling back to Level.
+func (p PrivacyConfig) LevelFor(repo string) string {
+	if level, ok := p.Repos[repo]; ok && level != "" {
+		return strings.ToLower(level)
+	}
+	return p.Level
+}
+
+// EstimationConfig controls task hours computed from commit timestamps.
+// Mode is "prior" (the computed hours guide the LLM and fill missing
+// estimates), "direct" (computed hours replace the LLM's) or "off".
+type EstimationConfig struct {
+	Mode           string
+	IdleGapMinutes int
+	LeadInMinutes  int
+}
+
+// IssueTrackerConfig is an [issues.<name>] section. Pattern may be empty for
+// "jira" and "github", which have built-in patterns. URL is a link template
+// using {key}, {number} and {project}.
+//
+// With Enrich set, issue titles and statuses are fetched from APIURL: the
+// Jira site (authenticated with Email and Token) or the GitHub REST API
+// (Token, with Repo as the owner/name for bare "#123" keys).
+type IssueTrackerConfig struct {
+	Name     string
+	Pattern  string
+	URL      string
+	Projects []string
+
+	Enrich bool
+	APIURL string
+	Email  string
+	Token  string
+	Repo   string
+}
+
+// SourcesConfig selects activity beyond the author's own commits. Merges
+// adds the author's merge commits and, through the [issues.github] API
+// settings, pull requests merged by Reviewer (a GitHub login); Reviews adds
+// pull requests reviewed by Reviewer. WorkingTree adds uncommitted changes and the stashes of the last
+// StashDays days to reports for today.
+type SourcesConfig struct {
+	Merges      bool
+	Reviews     bool
+	Reviewer    string
+	WorkingTree bool
+	StashDays   int
+}
+
 type Config struct {
 	Slack  SlackConfig
 	LLM    LLMConfig
 	Server ServerConfig
+	Report ReportConfig
+	Email  EmailConfig
+
+	Teams        WebhookConfig
+	Discord      WebhookConfig
+	Destinations DestinationsConfig
+	Git          GitConfig
+	Diff         DiffConfig
+	Detectors    DetectorsConfig
+	Privacy      PrivacyConfig
+	Estimation   EstimationConfig
+	Issues       []IssueTrackerConfig
+	Sources      SourcesConfig
 }
 
 func Load() (*Config, error) {
@@ -57,6 +227,57 @@ func Load() (*Config, error) {
 	slackSec := getSection(cfg, "slack", "Slack")
 	llmSec := getSection(cfg, "llm", "LLM")
 	serverSec := getSection(cfg, "server", "Server")
+	reportSec := getSection(cfg, "report", "Report")
+	emailSec := getSection(cfg, "email", "Email")
+	estimationSec := getSection(cfg, "estimation", "Estimation")
+	diffSec := getSection(cfg, "diff", "Diff")
+	sourcesSec := getSection(cfg, "sources", "Sources")
+	detectorsSec := getSection(cfg, "detectors", "Detectors")
+	privacySec := getSection(cfg, "privacy", "Privacy")
+
+	emailRepos := make(map[string][]string)
+	for repo, to := range sectionMap(getSection(cfg, "email.repos", "Email.Repos"), false) {
+		emailRepos[repo] = splitList(to)
+	}
+
+	repoDisable := make(map[string][]string)
+	for repo, names := range sectionMap(getSection(cfg, "detectors.disable", "Detectors.Disable"), false) {
+		repoDisable[repo] = splitList(strings.ToLower(names))
+	}
+	repoEnable := make(map[string][]string)
+	for repo, names := range sectionMap(getSection(cfg, "detectors.enable", "Detectors.Enable"), false) {
+		repoEnable[repo] = splitList(strings.ToLower(names))
+	}
+	weights := make(map[string]float64)
+	for _, key := range getSection(cfg, "detectors.weights", "Detectors.Weights").Keys() {
+		if w, err := key.Float64(); err == nil {
+			weights[strings.ToLower(key.Name())] = w
+		}
+	}
+
+	destRepos := make(map[string][]string)
+	for repo, dests := range sectionMap(getSection(cfg, "destinations.repos", "Destinations.Repos"), false) {
+		destRepos[repo] = splitList(strings.ToLower(dests))
+	}
+
+	var issues []IssueTrackerConfig
+	for _, sec := range cfg.Sections() {
+		name := strings.ToLower(sec.Name())
+		if !strings.HasPrefix(name, "issues.") {
+			continue
+		}
+		issues = append(issues, IssueTrackerConfig{
+			Name:     strings.TrimPrefix(name, "issues."),
+			Pattern:  strings.Trim(getKey(sec, "pattern", "Pattern").String(), "\""),
+			URL:      strings.Trim(getKey(sec, "url", "URL", "Url").String(), "\""),
+			Projects: splitList(getKey(sec, "projects", "Projects").String()),
+			Enrich:   getKey(sec, "enrich", "Enrich").MustBool(false),
+			APIURL:   strings.Trim(getKey(sec, "api_url", "ApiURL", "APIURL").String(), "\""),
+			Email:    strings.Trim(getKey(sec, "email", "Email").String(), "\""),
+			Token:    strings.Trim(getKey(sec, "token", "Token").String(), "\""),
+			Repo:     strings.Trim(getKey(sec, "repo", "Repo").String(), "\""),
+		})
+	}
 
 	return &Config{
 		Slack: SlackConfig{
@@ -79,6 +300,65 @@ func Load() (*Config, error) {
 			Port:              getKey(serverSec, "port", "Port").MustInt(8080),
 			AutoIncrementPort: getKey(serverSec, "auto_increment_port", "AutoIncrementPort").MustBool(true),
 		},
+		Report: ReportConfig{
+			Template:     strings.Trim(getKey(reportSec, "template", "Template").MustString("default"), "\""),
+			Repos:        sectionMap(getSection(cfg, "report.repos", "Report.Repos"), false),
+			Destinations: sectionMap(getSection(cfg, "report.destinations", "Report.Destinations"), true),
+		},
+		Email: EmailConfig{
+			Host:     strings.Trim(getKey(emailSec, "host", "Host").String(), "\""),
+			Port:     getKey(emailSec, "port", "Port").MustInt(587),
+			Username: strings.Trim(getKey(emailSec, "username", "Username", "user").String(), "\""),
+			Password: strings.Trim(getKey(emailSec, "password", "Password").String(), "\""),
+			From:     strings.Trim(getKey(emailSec, "from", "From").String(), "\""),
+			Security: strings.ToLower(strings.Trim(getKey(emailSec, "security", "Security").MustString("starttls"), "\"")),
+			To:       splitList(getKey(emailSec, "to", "To").String()),
+			Repos:    emailRepos,
+		},
+		Teams: WebhookConfig{
+			WebhookURL: strings.Trim(getKey(getSection(cfg, "teams", "Teams"), "webhook_url", "WebhookURL", "WebhookUrl").String(), "\""),
+		},
+		Discord: WebhookConfig{
+			WebhookURL: strings.Trim(getKey(getSection(cfg, "discord", "Discord"), "webhook_url", "WebhookURL", "WebhookUrl").String(), "\""),
+		},
+		Estimation: EstimationConfig{
+			Mode:           strings.ToLower(strings.Trim(getKey(estimationSec, "mode", "Mode").MustString("prior"), "\"")),
+			IdleGapMinutes: getKey(estimationSec, "idle_gap_minutes", "IdleGapMinutes").MustInt(120),
+			LeadInMinutes:  getKey(estimationSec, "lead_in_minutes", "LeadInMinutes").MustInt(30),
+		},
+		Git: GitConfig{
+			Backend: strings.ToLower(strings.Trim(getKey(getSection(cfg, "git", "Git"), "backend", "Backend").MustString("exec"), "\"")),
+		},
+		Diff: DiffConfig{
+			Exclude:        splitList(getKey(diffSec, "exclude", "Exclude").String()),
+			MaxFileLines:   getKey(diffSec, "max_file_lines", "MaxFileLines").MustInt(400),
+			MaxCommitLines: getKey(diffSec, "max_commit_lines", "MaxCommitLines").MustInt(2000),
+		},
+		Detectors: DetectorsConfig{
+			Auto:        getKey(detectorsSec, "auto", "Auto").MustBool(true),
+			Disable:     splitList(strings.ToLower(getKey(detectorsSec, "disable", "Disable").String())),
+			Enable:      splitList(strings.ToLower(getKey(detectorsSec, "enable", "Enable").String())),
+			RepoDisable: repoDisable,
+			RepoEnable:  repoEnable,
+			Weights:     weights,
+		},
+		Privacy: PrivacyConfig{
+			Level:          strings.ToLower(strings.Trim(getKey(privacySec, "level", "Level").MustString("full"), "\"")),
+			Repos:          sectionMap(getSection(cfg, "privacy.repos", "Privacy.Repos"), false),
+			AnonymizePaths: getKey(privacySec, "anonymize_paths", "AnonymizePaths").MustBool(false),
+		},
+		Destinations: DestinationsConfig{
+			Default: splitList(strings.ToLower(getKey(getSection(cfg, "destinations", "Destinations"), "default", "Default").String())),
+			Repos:   destRepos,
+		},
+		Issues: issues,
+		Sources: SourcesConfig{
+			Merges:      getKey(sourcesSec, "merges", "Merges").MustBool(false),
+			Reviews:     getKey(sourcesSec, "reviews", "Reviews").MustBool(false),
+			Reviewer:    strings.Trim(getKey(sourcesSec, "reviewer", "Reviewer").String(), "\""),
+			WorkingTree: getKey(sourcesSec, "working_tree", "WorkingTree").MustBool(false),
+			StashDays:   getKey(sourcesSec, "stash_days", "StashDays").MustInt(7),
+		},
 	}, nil
 }
 
@@ -100,3 +380,27 @@ func getKey(sec *ini.Section, keys ...string) *ini.Key {
 	// Return the first one so Must* functions can handle the default on it
 	return sec.Key(keys[0])
 }
+
+// sectionMap reads every key of a section into a map, e.g. repo = template.
+func sectionMap(sec *ini.Section, lowerKeys bool) map[string]string {
+	out := make(map[string]string)
+	for _, key := range sec.Keys() {
+		name := key.Name()
+		if lowerKeys {
+			name = strings.ToLower(name)
+		}
+		out[name] = strings.Trim(key.String(), "\"")
+	}
+	return out
+}
+
+// splitList splits a comma separated value, dropping empty entries.
+func splitList(value string) []string {
+	var out []string
+	for _, part := range strings.Split(strings.Trim(value, "\""), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -68,3 +68,267 @@ token=TEST_TOKEN
 		t.Fatalf("expected empty base_url by default, got %q", cfg.LLM.BaseURL)
 	}
 }
+
+func TestLoadReportTemplateSelection(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.ini")
+	content := `
+[report]
+template=yesterday_today
+
+[report.repos]
+billing=done_doing_next
+
+[report.destinations]
+Slack=default
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer os.Chdir(cwd)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cfg.Report.TemplateFor("web", ""); got != "yesterday_today" {
+		t.Fatalf("expected default template, got %q", got)
+	}
+	if got := cfg.Report.TemplateFor("billing", ""); got != "done_doing_next" {
+		t.Fatalf("expected repo template, got %q", got)
+	}
+	if got := cfg.Report.TemplateFor("billing", "slack"); got != "default" {
+		t.Fatalf("expected destination template, got %q", got)
+	}
+}
+
+func TestLoadEmailRecipientsPerRepo(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.ini")
+	content := `
+[email]
+host=smtp.example.com
+port=465
+security=tls
+from=bot@example.com
+to=team@example.com
+
+[email.repos]
+billing=finance@example.com, cfo@example.com
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer os.Chdir(cwd)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Email.Port != 465 || cfg.Email.Security != "tls" {
+		t.Fatalf("unexpected email config: %+v", cfg.Email)
+	}
+	if got := cfg.Email.RecipientsFor("billing"); len(got) != 2 || got[1] != "cfo@example.com" {
+		t.Fatalf("unexpected repo recipients: %#v", got)
+	}
+	if got := cfg.Email.RecipientsFor("web"); len(got) != 1 || got[0] != "team@example.com" {
+		t.Fatalf("unexpected default recipients: %#v", got)
+	}
+}
+
+func TestLoadDestinations(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.ini")
+	content := `
+[teams]
+webhook_url=https://example.webhook.office.com/x
+
+[destinations]
+default=Slack, teams
+
+[destinations.repos]
+oss-tool=discord
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer os.Chdir(cwd)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Teams.WebhookURL != "https://example.webhook.office.com/x" {
+		t.Fatalf("unexpected teams config: %+v", cfg.Teams)
+	}
+	if got := cfg.Destinations.For("web"); len(got) != 2 || got[0] != "slack" || got[1] != "teams" {
+		t.Fatalf("unexpected default destinations: %#v", got)
+	}
+	if got := cfg.Destinations.For("oss-tool"); len(got) != 1 || got[0] != "discord" {
+		t.Fatalf("unexpected repo destinations: %#v", got)
+	}
+}
+
+func TestLoadGitBackend(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.ini")
+	if err := os.WriteFile(cfgPath, []byte("[git]\nbackend=Go-Git\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer os.Chdir(cwd)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Git.Backend != "go-git" {
+		t.Fatalf("unexpected git backend: %q", cfg.Git.Backend)
+	}
+	if cfg.Sources.Merges || cfg.Sources.Reviews || cfg.Sources.WorkingTree || cfg.Sources.StashDays != 7 {
+		t.Fatalf("unexpected source defaults: %+v", cfg.Sources)
+	}
+	if len(cfg.Diff.Exclude) != 0 || cfg.Diff.MaxFileLines != 400 || cfg.Diff.MaxCommitLines != 2000 {
+		t.Fatalf("unexpected diff defaults: %+v", cfg.Diff)
+	}
+	if !cfg.Detectors.Auto || len(cfg.Detectors.Disable) != 0 || len(cfg.Detectors.Weights) != 0 {
+		t.Fatalf("unexpected detector defaults: %+v", cfg.Detectors)
+	}
+	if cfg.Estimation.Mode != "prior" || cfg.Estimation.IdleGapMinutes != 120 || cfg.Estimation.LeadInMinutes != 30 {
+		t.Fatalf("unexpected estimation defaults: %+v", cfg.Estimation)
+	}
+}
+
+func TestLoadDetectors(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.ini")
+	content := `
+[detectors]
+auto=false
+disable=Style, ux_bugfix
+
+[detectors.disable]
+api=nextjs, drizzle
+
+[detectors.enable]
+admin=drizzle
+
+[detectors.weights]
+logic=0.1
+auth=high
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer os.Chdir(cwd)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := cfg.Detectors
+	if d.Auto || len(d.Disable) != 2 || d.Disable[0] != "style" || d.Disable[1] != "ux_bugfix" {
+		t.Fatalf("unexpected detectors: %+v", d)
+	}
+	if got := d.RepoDisable["api"]; len(got) != 2 || got[0] != "nextjs" || got[1] != "drizzle" {
+		t.Fatalf("unexpected repo disable: %#v", d.RepoDisable)
+	}
+	if got := d.RepoEnable["admin"]; len(got) != 1 || got[0] != "drizzle" {
+		t.Fatalf("unexpected repo enable: %#v", d.RepoEnable)
+	}
+	if len(d.Weights) != 1 || d.Weights["logic"] != 0.1 {
+		t.Fatalf("expected invalid weights to be skipped: %#v", d.Weights)
+	}
+}
+
+func TestLoadPrivacy(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.ini")
+	content := `
+[privacy]
+anonymize_paths=true
+
+[privacy.repos]
+client-portal=Metadata
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer os.Chdir(cwd)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := cfg.Privacy
+	if !p.AnonymizePaths {
+		t.Fatal("expected path anonymisation")
+	}
+	if got := p.LevelFor("client-portal"); got != "metadata" {
+		t.Fatalf("expected metadata for client-portal, got %q", got)
+	}
+	if got := p.LevelFor("md2slack"); got != "full" {
+		t.Fatalf("expected full by default, got %q", got)
+	}
+}
+
+func TestLoadIssueTrackers(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.ini")
+	content := `
+[issues.jira]
+url=https://acme.atlassian.net/browse/{key}
+projects=PROJ, OPS
+enrich=true
+api_url=https://acme.atlassian.net
+
+[issues.github]
+pattern=` + "`#\\d+`" + `
+url=https://github.com/acme/app/issues/{number}
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cwd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer os.Chdir(cwd)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Issues) != 2 {
+		t.Fatalf("unexpected issue trackers: %+v", cfg.Issues)
+	}
+	jira, github := cfg.Issues[0], cfg.Issues[1]
+	if jira.Name != "jira" || jira.Pattern != "" || len(jira.Projects) != 2 || jira.URL != "https://acme.atlassian.net/browse/{key}" {
+		t.Fatalf("unexpected jira config: %+v", jira)
+	}
+	if !jira.Enrich || jira.APIURL != "https://acme.atlassian.net" {
+		t.Fatalf("unexpected jira enrichment config: %+v", jira)
+	}
+	if github.Name != "github" || github.Pattern != `#\d+` || github.Enrich {
+		t.Fatalf("unexpected github config: %+v", github)
+	}
+}
@@ -0,0 +1,167 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxTitle       = 256
+	maxDescription = 4096
+	embedColor     = 0xF97316
+	// maxRetryWait caps how long a rate-limited post waits for its retry.
+	maxRetryWait = 30 * time.Second
+)
+
+// sleep waits out rate limits; tests replace it.
+var sleep = time.Sleep
+
+// Notifier posts reports to a Discord channel through a webhook.
+type Notifier struct {
+	WebhookURL string
+}
+
+func (n *Notifier) Name() string { return "discord" }
+
+func (n *Notifier) Send(subject string, markdown string) error {
+	return SendMarkdown(n.WebhookURL, subject, markdown)
+}
+
+// SendMarkdown posts the report as one or more embeds. Reports longer than
+// an embed description are split on line boundaries, one embed per message
+// so no message exceeds Discord's total embed size.
+func SendMarkdown(webhookURL string, subject string, markdown string) error {
+	if webhookURL == "" {
+		return fmt.Errorf("please configure webhook_url in the [discord] section of config.ini")
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	for _, embed := range ConvertToEmbeds(subject, markdown) {
+		payload, err := json.Marshal(map[string]interface{}{
+			"embeds": []interface{}{embed},
+		})
+		if err != nil {
+			return err
+		}
+		if err := post(client, webhookURL, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post sends one message. When rate limited it waits as long as Discord
+// asks and retries once.
+func post(client *http.Client, webhookURL string, payload []byte) error {
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			wait := retryAfter(resp.Header, body)
+			if attempt == 0 && wait <= maxRetryWait {
+				sleep(wait)
+				continue
+			}
+			return fmt.Errorf("discord rate limit: retry after %s", wait)
+		}
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("discord error: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+		return nil
+	}
+}
+
+// retryAfter reads how long a rate-limited request should wait: retry_after
+// in seconds from the body, or the Retry-After header.
+func retryAfter(header http.Header, body []byte) time.Duration {
+	var limit struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if json.Unmarshal(body, &limit) == nil && limit.RetryAfter > 0 {
+		return time.Duration(limit.RetryAfter * float64(time.Second))
+	}
+	if secs, err := strconv.ParseFloat(header.Get("Retry-After"), 64); err == nil && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	return time.Second
+}
+
+// ConvertToEmbeds splits the report markdown into embeds. Discord renders
+// the same markdown subset the report uses, so the text is kept as is.
+func ConvertToEmbeds(subject string, markdown string) []map[string]interface{} {
+	var embeds []map[string]interface{}
+	for i, chunk := range splitLines(strings.TrimSpace(markdown), maxDescription) {
+		embed := map[string]interface{}{
+			"description": chunk,
+			"color":       embedColor,
+		}
+		if i == 0 && subject != "" {
+			embed["title"] = truncate(subject, maxTitle)
+		}
+		embeds = append(embeds, embed)
+	}
+	return embeds
+}
+
+// splitLines splits text into chunks of at most limit bytes, breaking on
+// newlines where possible and keeping code fences balanced across chunks.
+func splitLines(text string, limit int) []string {
+	if len(text) <= limit {
+		return []string{text}
+	}
+	var chunks []string
+	var cur strings.Builder
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		// Leave room for reopening and closing a code fence
+		line = truncate(line, limit-8)
+		if cur.Len()+len(line)+1 > limit-4 {
+			chunks = appendChunk(chunks, &cur, inFence)
+			if inFence {
+				cur.WriteString("```\n")
+			}
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		cur.WriteString(line)
+		cur.WriteString("\n")
+	}
+	return appendChunk(chunks, &cur, false)
+}
+
+func appendChunk(chunks []string, cur *strings.Builder, closeFence bool) []string {
+	text := strings.TrimRight(cur.String(), "\n")
+	cur.Reset()
+	if text == "" {
+		return chunks
+	}
+	if closeFence {
+		text += "\n```"
+	}
+	return append(chunks, text)
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit - len("…")
+	for cut > 0 && !isRuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,99 @@
+package discord
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvertToEmbedsSplitsLongReports(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("**Tasks**\n")
+	for i := 0; i < 200; i++ {
+		sb.WriteString("- a task line that is reasonably long to fill the embed quickly ✅\n")
+	}
+	embeds := ConvertToEmbeds("Daily Status Report", sb.String())
+	if len(embeds) < 2 {
+		t.Fatalf("expected report split across embeds, got %d", len(embeds))
+	}
+	for i, e := range embeds {
+		if desc := e["description"].(string); len(desc) > maxDescription {
+			t.Fatalf("embed %d too long: %d", i, len(desc))
+		}
+		if _, ok := e["title"]; ok != (i == 0) {
+			t.Fatalf("expected title only on first embed")
+		}
+	}
+}
+
+func TestSplitLinesKeepsFencesBalanced(t *testing.T) {
+	text := "```\n" + strings.Repeat("line\n", 30) + "```"
+	for _, chunk := range splitLines(text, 64) {
+		if strings.Count(chunk, "```")%2 != 0 {
+			t.Fatalf("unbalanced fence in chunk %q", chunk)
+		}
+	}
+}
+
+func TestSendMarkdownPostsEmbeds(t *testing.T) {
+	var posts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Embeds []map[string]interface{} `json:"embeds"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &payload); err != nil || len(payload.Embeds) != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		posts++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if err := SendMarkdown(srv.URL, "Report", "**Tasks**\n- one\n"); err != nil {
+		t.Fatal(err)
+	}
+	if posts != 1 {
+		t.Fatalf("expected 1 post, got %d", posts)
+	}
+}
+
+func TestSendMarkdownRetriesOnceWhenRateLimited(t *testing.T) {
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { sleep = time.Sleep }()
+
+	var posts int
+	limited := 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		if posts <= limited {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"You are being rate limited.","retry_after":1.5,"global":false}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if err := SendMarkdown(srv.URL, "Report", "- one"); err != nil {
+		t.Fatal(err)
+	}
+	if posts != 2 || len(waits) != 1 || waits[0] != 1500*time.Millisecond {
+		t.Fatalf("expected one retry after 1.5s, got %d posts and waits %v", posts, waits)
+	}
+
+	posts, limited = 0, 2
+	err := SendMarkdown(srv.URL, "Report", "- one")
+	if err == nil || !strings.Contains(err.Error(), "rate limit") {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	if posts != 2 {
+		t.Fatalf("expected a single retry, got %d posts", posts)
+	}
+}
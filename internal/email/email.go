@@ -0,0 +1,183 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"md2slack/internal/config"
+	"md2slack/internal/renderer"
+)
+
+// Notifier sends reports to a fixed list of recipients.
+type Notifier struct {
+	Config *config.EmailConfig
+	To     []string
+	Date   string // report date, shown in the HTML title
+}
+
+func (n *Notifier) Name() string { return "email" }
+
+func (n *Notifier) Send(subject string, markdown string) error {
+	return SendMarkdown(n.Config, n.To, subject, markdown, n.Date)
+}
+
+// SendMarkdown renders the report for date as HTML with a plain text
+// alternative and sends it to the given recipients.
+func SendMarkdown(cfg *config.EmailConfig, to []string, subject string, markdown string, date string) error {
+	if cfg.Host == "" || cfg.From == "" {
+		return fmt.Errorf("please configure host and from in the [email] section of config.ini")
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("no email recipients configured")
+	}
+	if _, err := security(cfg); err != nil {
+		return err
+	}
+
+	report := renderer.Report{Date: date}
+	htmlBody, err := renderer.RenderFormat("html", markdown, report)
+	if err != nil {
+		return err
+	}
+	textBody, err := renderer.RenderFormat("text", markdown, report)
+	if err != nil {
+		return err
+	}
+
+	msg, err := buildMessage(cfg.From, to, subject, textBody, htmlBody)
+	if err != nil {
+		return err
+	}
+	return send(cfg, to, msg)
+}
+
+func buildMessage(from string, to []string, subject string, textBody string, htmlBody string) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", textBody},
+		{"text/html; charset=utf-8", htmlBody},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "md2slack-" + hex.EncodeToString(b), nil
+}
+
+// security returns the normalised security setting. Anything but starttls,
+// tls or none is an error rather than a silent plaintext connection; empty
+// means starttls, as in config.ini.
+func security(cfg *config.EmailConfig) (string, error) {
+	switch s := strings.ToLower(strings.TrimSpace(cfg.Security)); s {
+	case "":
+		return "starttls", nil
+	case "starttls", "tls", "none":
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown security %q in the [email] section of config.ini (want starttls, tls or none)", cfg.Security)
+	}
+}
+
+func send(cfg *config.EmailConfig, to []string, msg []byte) error {
+	mode, err := security(cfg)
+	if err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if mode == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if mode == "starttls" {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s does not support STARTTLS", addr)
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
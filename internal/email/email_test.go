@@ -0,0 +1,141 @@
+package email
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"md2slack/internal/config"
+)
+
+type capture struct {
+	from string
+	to   []string
+	data string
+}
+
+// startCaptureServer runs a minimal plaintext SMTP server that records one message.
+func startCaptureServer(t *testing.T) (int, <-chan capture) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan capture, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var msg capture
+		reply("220 localhost ESMTP capture")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimSpace(line)
+			upper := strings.ToUpper(cmd)
+			switch {
+			case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(upper, "MAIL FROM:"):
+				msg.from = strings.Trim(cmd[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(upper, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(cmd[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case upper == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				msg.data = data.String()
+				reply("250 OK")
+			case upper == "QUIT":
+				reply("221 Bye")
+				out <- msg
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, out
+}
+
+func TestSendMarkdownMultipart(t *testing.T) {
+	port, captured := startCaptureServer(t)
+	cfg := &config.EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		From:     "bot@example.com",
+		Security: "none",
+	}
+
+	err := SendMarkdown(cfg, []string{"pm@example.com", "lead@example.com"}, "Daily Status Report 2026-02-05", "**Tasks**\n- Ship email ✅\n", "2026-02-05")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := <-captured
+	if msg.from != "bot@example.com" || len(msg.to) != 2 {
+		t.Fatalf("unexpected envelope: %+v", msg)
+	}
+	for _, want := range []string{
+		"Content-Type: multipart/alternative",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+		"<strong>Tasks</strong>",
+		"<title>Daily Status Report 2026-02-05</title>",
+		"To: pm@example.com, lead@example.com",
+	} {
+		if !strings.Contains(msg.data, want) {
+			t.Fatalf("expected %q in message:\n%s", want, msg.data)
+		}
+	}
+	if strings.Contains(strings.SplitN(msg.data, "text/html", 2)[0], "**Tasks**") {
+		t.Fatalf("expected markdown stripped from plain text part:\n%s", msg.data)
+	}
+}
+
+func TestSendMarkdownRequiresRecipients(t *testing.T) {
+	cfg := &config.EmailConfig{Host: "127.0.0.1", Port: 25, From: "bot@example.com"}
+	if err := SendMarkdown(cfg, nil, "s", "body", ""); err == nil {
+		t.Fatal("expected error without recipients")
+	}
+	cfg.Host = ""
+	if err := SendMarkdown(cfg, []string{"a@example.com"}, "s", "body", ""); err == nil || !strings.Contains(err.Error(), "host") {
+		t.Fatalf("expected config error, got %v", err)
+	}
+}
+
+func TestSendMarkdownRejectsUnknownSecurity(t *testing.T) {
+	for _, security := range []string{"ssl", "tsl", "plain"} {
+		cfg := &config.EmailConfig{Host: "127.0.0.1", Port: 25, From: "bot@example.com", Security: security}
+		err := SendMarkdown(cfg, []string{"a@example.com"}, "s", "body", "")
+		if err == nil || !strings.Contains(err.Error(), "unknown security") {
+			t.Fatalf("%s: expected a config error, got %v", security, err)
+		}
+	}
+	for in, want := range map[string]string{"TLS": "tls", " StartTLS ": "starttls", "": "starttls", "None": "none"} {
+		if got, err := security(&config.EmailConfig{Security: in}); err != nil || got != want {
+			t.Fatalf("security(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package gitdiff
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ActivityMerge  = "merge"
+	ActivityReview = "review"
+)
+
+// Activity is work that does not show up as the author's own commits: merge
+// commits the author made, and code reviews.
+type Activity struct {
+	Kind   string    `json:"kind"`             // ActivityMerge or ActivityReview
+	Ref    string    `json:"ref,omitempty"`    // "#12", "owner/repo#12" or a branch name
+	Title  string    `json:"title"`            // PR title or merged subject
+	Author string    `json:"author,omitempty"` // author of the merged or reviewed work
+	Hash   string    `json:"commit,omitempty"` // merge commit
+	URL    string    `json:"url,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+var (
+	mergePullRe   = regexp.MustCompile(`^Merge pull request (#\d+) from (\S+)`)
+	mergeBranchRe = regexp.MustCompile(`^Merge (?:remote-tracking )?branch '([^']+)'`)
+)
+
+// MergeActivity returns the merge commits the author made on a day, as
+// author or committer: a pull request merged in the GitHub web UI is
+// authored by whoever merged it and committed by GitHub. Squashed, rebased
+// and cherry-picked commits are not counted: locally they cannot be told
+// apart from other commits the author happened to commit.
+func MergeActivity(date string, repoPath string, authorOverride string) ([]Activity, error) {
+	day, err := time.ParseInLocation("2006-01-02", ISODate(date), time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
+	}
+	patterns := authorPatterns(repoPath, authorOverride)
+	if len(patterns) == 1 && patterns[0] == "" {
+		// Without a known user every merge would count as theirs.
+		return nil, nil
+	}
+	q := CommitQuery{
+		Since:      day,
+		Until:      day.Add(24*time.Hour - time.Second),
+		MergesOnly: true,
+		NoPatch:    true,
+	}
+	byAuthor, byCommitter := q, q
+	byAuthor.Authors = patterns
+	byCommitter.Committers = patterns
+
+	var merges []Commit
+	seen := make(map[string]bool)
+	for _, cq := range []CommitQuery{byAuthor, byCommitter} {
+		commits, err := CurrentBackend().Commits(repoPath, cq)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range commits {
+			if !seen[c.Hash] {
+				seen[c.Hash] = true
+				merges = append(merges, c)
+			}
+		}
+	}
+	sort.SliceStable(merges, func(i, j int) bool { return merges[i].CommittedAt.After(merges[j].CommittedAt) })
+
+	out := make([]Activity, 0, len(merges))
+	for _, c := range merges {
+		out = append(out, mergeCommitActivity(c))
+	}
+	return out, nil
+}
+
+// mergeCommitActivity describes a merge commit. GitHub merge commits carry
+// the pull request title as the first body line.
+func mergeCommitActivity(c Commit) Activity {
+	a := Activity{Kind: ActivityMerge, Title: c.Message, Hash: c.Hash, At: c.CommittedAt}
+	if m := mergePullRe.FindStringSubmatch(c.Message); m != nil {
+		a.Ref = m[1]
+		a.Title = m[2]
+		if title, _, _ := strings.Cut(strings.TrimSpace(c.Body), "\n"); title != "" {
+			a.Title = title
+		}
+		if owner, _, ok := strings.Cut(m[2], "/"); ok {
+			a.Author = owner
+		}
+	} else if m := mergeBranchRe.FindStringSubmatch(c.Message); m != nil {
+		a.Ref = m[1]
+		a.Title = m[1]
+	}
+	return a
+}
+
+// ActivityTask turns an activity into a done task of type "merge" or "review".
+func ActivityTask(a Activity) TaskChange {
+	var intent string
+	switch {
+	case a.Kind == ActivityReview:
+		intent = fmt.Sprintf("review PR %s: %s", a.Ref, a.Title)
+	case strings.Contains(a.Ref, "#"):
+		intent = fmt.Sprintf("merge PR %s: %s", a.Ref, a.Title)
+	case a.Ref != "":
+		intent = fmt.Sprintf("merge branch %s", a.Ref)
+	default:
+		intent = fmt.Sprintf("merge %q", a.Title)
+	}
+	t := TaskChange{
+		TaskType:   a.Kind,
+		TaskIntent: intent,
+		Scope:      a.Ref,
+		Status:     "done",
+		Confidence: 1,
+	}
+	if a.Author != "" {
+		t.TechnicalWhy = fmt.Sprintf("Work by %s.", a.Author)
+	}
+	if a.URL != "" {
+		t.TechnicalWhy = strings.TrimSpace(t.TechnicalWhy + "\n" + a.URL)
+	}
+	if a.Hash != "" {
+		t.Commits = []string{a.Hash}
+	}
+	return t
+}
+
+// AppendActivityTasks adds a task per activity, skipping activities that
+// already have a task, e.g. when a day is re-run from history.
+func AppendActivityTasks(tasks []TaskChange, activity []Activity) []TaskChange {
+	existing := make(map[string]bool)
+	for _, t := range tasks {
+		existing[t.TaskType+"\x00"+t.TaskIntent] = true
+	}
+	for _, a := range activity {
+		t := ActivityTask(a)
+		if existing[t.TaskType+"\x00"+t.TaskIntent] {
+			continue
+		}
+		existing[t.TaskType+"\x00"+t.TaskIntent] = true
+		tasks = append(tasks, t)
+	}
+	return tasks
+}
@@ -0,0 +1,112 @@
+package gitdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeActivity(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{"a.txt": "a\n"})
+	if _, err := Git(dir, "branch", "-M", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Git(dir, "checkout", "-q", "-b", "feat-x"); err != nil {
+		t.Fatal(err)
+	}
+	writeAndCommit(t, dir, "Bruno", "2026-02-05T09:00:00", map[string]string{"b.txt": "b\n"})
+	if _, err := Git(dir, "checkout", "-q", "-b", "fix-cart", "main"); err != nil {
+		t.Fatal(err)
+	}
+	writeAndCommit(t, dir, "Bruno", "2026-02-05T09:30:00", map[string]string{"c.txt": "c\n"})
+	if _, err := Git(dir, "-c", "user.name=Bruno", "-c", "user.email=bruno@example.com",
+		"commit", "-q", "--amend", "-m", "Fix cart totals (#13)"); err != nil {
+		t.Fatal(err)
+	}
+	cartTip, _ := Git(dir, "rev-parse", "HEAD")
+
+	if _, err := Git(dir, "checkout", "-q", "main"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_COMMITTER_DATE", "2026-02-05T11:00:00")
+	ana := []string{"-c", "user.name=Ana", "-c", "user.email=ana@example.com"}
+	if _, err := Git(dir, append(ana, "merge", "-q", "--no-ff", "feat-x", "-m", "Merge pull request #12 from bruno/feat-x\n\nRetry webhook delivery")...); err != nil {
+		t.Fatal(err)
+	}
+	// Neither a commit Ana cherry-picked nor her own commit is merge activity.
+	if _, err := Git(dir, append(ana, "cherry-pick", strings.TrimSpace(cartTip))...); err != nil {
+		t.Fatal(err)
+	}
+	writeAndCommit(t, dir, "Ana", "2026-02-05T12:00:00", map[string]string{"d.txt": "d\n"})
+	t.Setenv("GIT_COMMITTER_DATE", "2026-02-05T13:00:00")
+	if _, err := Git(dir, append(ana, "merge", "-q", "--no-ff", "--no-edit", "fix-cart")...); err != nil {
+		t.Fatal(err)
+	}
+
+	// A pull request merged in the GitHub web UI: Ana authors the merge,
+	// GitHub commits it.
+	if _, err := Git(dir, "checkout", "-q", "-b", "docs", "main~3"); err != nil {
+		t.Fatal(err)
+	}
+	writeAndCommit(t, dir, "Bruno", "2026-02-05T13:30:00", map[string]string{"docs.txt": "docs\n"})
+	if _, err := Git(dir, "checkout", "-q", "main"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_COMMITTER_DATE", "2026-02-05T14:00:00")
+	t.Setenv("GIT_AUTHOR_NAME", "Ana")
+	t.Setenv("GIT_AUTHOR_EMAIL", "ana@example.com")
+	if _, err := Git(dir, "-c", "user.name=GitHub", "-c", "user.email=noreply@github.com",
+		"merge", "-q", "--no-ff", "docs", "-m", "Merge pull request #14 from bruno/docs\n\nDocument retries"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"merge|#14|Document retries|bruno",
+		"merge|fix-cart|fix-cart|",
+		"merge|#12|Retry webhook delivery|bruno",
+	}
+	for _, b := range []Backend{ExecBackend{}, GoGitBackend{}} {
+		SetBackend(b)
+		acts, err := MergeActivity("2026-02-05", dir, "Ana")
+		if err != nil {
+			t.Fatalf("%s: %v", b.Name(), err)
+		}
+		var got []string
+		for _, a := range acts {
+			got = append(got, strings.Join([]string{a.Kind, a.Ref, a.Title, a.Author}, "|"))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: unexpected activity %v", b.Name(), got)
+		}
+	}
+	SetBackend(ExecBackend{})
+}
+
+func TestAppendActivityTasks(t *testing.T) {
+	acts := []Activity{
+		{Kind: ActivityMerge, Ref: "#12", Title: "Retry webhook delivery", Author: "bruno", Hash: "aaaaaaa"},
+		{Kind: ActivityMerge, Ref: "feat/x", Title: "feat/x", Hash: "bbbbbbb"},
+		{Kind: ActivityReview, Ref: "acme/api#7", Title: "Add caching", URL: "https://github.com/acme/api/pull/7"},
+	}
+	tasks := AppendActivityTasks(nil, acts)
+	tasks = AppendActivityTasks(tasks, acts)
+	if len(tasks) != 3 {
+		t.Fatalf("expected activity tasks once, got %+v", tasks)
+	}
+	var intents []string
+	for _, task := range tasks {
+		intents = append(intents, task.TaskType+": "+task.TaskIntent)
+	}
+	want := []string{
+		"merge: merge PR #12: Retry webhook delivery",
+		"merge: merge branch feat/x",
+		"review: review PR acme/api#7: Add caching",
+	}
+	if !reflect.DeepEqual(intents, want) {
+		t.Fatalf("unexpected intents: %v", intents)
+	}
+	if tasks[2].TechnicalWhy != "https://github.com/acme/api/pull/7" || tasks[0].Status != "done" {
+		t.Fatalf("unexpected review task: %+v", tasks[2])
+	}
+}
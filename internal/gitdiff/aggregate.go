@@ -0,0 +1,137 @@
+package gitdiff
+
+import (
+	"math"
+	"sort"
+)
+
+// SignalSummary is a commit's signals counted and ranked, strongest first,
+// with the strongest files rolled up. It replaces the raw per-file signals
+// where prompt space matters.
+type SignalSummary struct {
+	Signals []RankedSignal `json:"signals"`
+	Files   []FileRollup   `json:"files,omitempty"`
+	// OmittedFiles are files with signals left out of Files.
+	OmittedFiles int `json:"omitted_files,omitempty"`
+}
+
+// RankedSignal is one signal type across a commit. Count adds up the
+// occurrences in every file; Weight is the highest detector weight.
+type RankedSignal struct {
+	Type     SignalType `json:"type"`
+	Count    int        `json:"count"`
+	Files    int        `json:"files"`
+	Weight   float64    `json:"weight"`
+	Strength float64    `json:"strength"`
+}
+
+// FileRollup is one file's signals, strongest first, with a few of its
+// hints and locations.
+type FileRollup struct {
+	File         string       `json:"file"`
+	Types        []SignalType `json:"types"`
+	Strength     float64      `json:"strength"`
+	Hints        []string     `json:"hints,omitempty"`
+	OmittedHints int          `json:"omitted_hints,omitempty"`
+	Locations    []Location   `json:"locations,omitempty"`
+}
+
+// Caps on a SignalSummary, so a large refactor stays a short prompt.
+const (
+	maxRollupFiles     = 8
+	maxRollupHints     = 3
+	maxRollupLocations = 2
+)
+
+// strength ranks a signal: its weight, growing slowly with the number of
+// occurrences so repeated hits count for more without drowning out a
+// single strong one.
+func strength(weight float64, count int) float64 {
+	if count < 1 {
+		count = 1
+	}
+	return round2(weight * (1 + math.Log2(float64(count))))
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// signalWeight returns the weight of a type in a signal. Signals built
+// without weights count as structural.
+func signalWeight(s Signal, t SignalType) float64 {
+	if w, ok := s.Weights[t]; ok {
+		return w
+	}
+	return structuralWeight
+}
+
+func signalCount(s Signal, t SignalType) int {
+	if n := s.Counts[t]; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// SummarizeSignals aggregates a commit's signals by type, ranked by
+// strength, and rolls up the strongest files.
+func SummarizeSignals(signals []Signal) SignalSummary {
+	byType := make(map[SignalType]*RankedSignal)
+	var order []SignalType
+	var files []FileRollup
+	for _, s := range signals {
+		if len(s.Types) == 0 {
+			continue
+		}
+		f := FileRollup{File: s.File}
+		fileStrength := make(map[SignalType]float64, len(s.Types))
+		for _, t := range s.Types {
+			w, n := signalWeight(s, t), signalCount(s, t)
+			r, ok := byType[t]
+			if !ok {
+				r = &RankedSignal{Type: t}
+				byType[t] = r
+				order = append(order, t)
+			}
+			r.Count += n
+			r.Files++
+			r.Weight = max(r.Weight, w)
+
+			fileStrength[t] = strength(w, n)
+			f.Strength = max(f.Strength, fileStrength[t])
+			f.Types = append(f.Types, t)
+		}
+		sort.SliceStable(f.Types, func(i, j int) bool {
+			return fileStrength[f.Types[i]] > fileStrength[f.Types[j]]
+		})
+		f.Hints = s.Hints
+		if len(f.Hints) > maxRollupHints {
+			f.OmittedHints = len(f.Hints) - maxRollupHints
+			f.Hints = f.Hints[:maxRollupHints]
+		}
+		f.Locations = s.Locations
+		if len(f.Locations) > maxRollupLocations {
+			f.Locations = f.Locations[:maxRollupLocations]
+		}
+		files = append(files, f)
+	}
+
+	var out SignalSummary
+	for _, t := range order {
+		r := byType[t]
+		r.Strength = strength(r.Weight, r.Count)
+		out.Signals = append(out.Signals, *r)
+	}
+	sort.SliceStable(out.Signals, func(i, j int) bool {
+		return out.Signals[i].Strength > out.Signals[j].Strength
+	})
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Strength > files[j].Strength
+	})
+	if len(files) > maxRollupFiles {
+		out.OmittedFiles = len(files) - maxRollupFiles
+		files = files[:maxRollupFiles]
+	}
+	out.Files = files
+	return out
+}
@@ -0,0 +1,55 @@
+package gitdiff
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeSignals(t *testing.T) {
+	var signals []Signal
+	for i := 0; i < 12; i++ {
+		signals = append(signals, Signal{
+			File:    fmt.Sprintf("pkg/handler%d.go", i),
+			Types:   []SignalType{SignalLogicChange},
+			Hints:   []string{"conditional logic changed"},
+			Weights: map[SignalType]float64{SignalLogicChange: 0.2},
+			Counts:  map[SignalType]int{SignalLogicChange: 4},
+		})
+	}
+	signals = append(signals, Signal{
+		File:    "db/migrations/003.sql",
+		Types:   []SignalType{SignalLogicChange, SignalSchemaChange},
+		Hints:   []string{"a", "b", "c", "d", "e"},
+		Weights: map[SignalType]float64{SignalLogicChange: 0.2, SignalSchemaChange: 0.7},
+		Counts:  map[SignalType]int{SignalLogicChange: 1, SignalSchemaChange: 2},
+	}, Signal{File: "README.md"})
+
+	sum := SummarizeSignals(signals)
+
+	want := []RankedSignal{
+		{Type: SignalSchemaChange, Count: 2, Files: 1, Weight: 0.7, Strength: 1.4},
+		{Type: SignalLogicChange, Count: 49, Files: 13, Weight: 0.2, Strength: 1.32},
+	}
+	if !reflect.DeepEqual(sum.Signals, want) {
+		t.Fatalf("unexpected ranking: %+v", sum.Signals)
+	}
+
+	if len(sum.Files) != maxRollupFiles || sum.OmittedFiles != 13-maxRollupFiles {
+		t.Fatalf("expected %d files and the rest omitted, got %d and %d", maxRollupFiles, len(sum.Files), sum.OmittedFiles)
+	}
+	top := sum.Files[0]
+	if top.File != "db/migrations/003.sql" || !reflect.DeepEqual(top.Types, []SignalType{SignalSchemaChange, SignalLogicChange}) {
+		t.Fatalf("unexpected top file: %+v", top)
+	}
+	if !reflect.DeepEqual(top.Hints, []string{"a", "b", "c"}) || top.OmittedHints != 2 {
+		t.Fatalf("expected hints to be capped, got %+v", top)
+	}
+}
+
+func TestSummarizeSignalsWithoutWeights(t *testing.T) {
+	sum := SummarizeSignals([]Signal{{File: "a.go", Types: []SignalType{SignalNewFile}}})
+	if len(sum.Signals) != 1 || sum.Signals[0].Weight != structuralWeight || sum.Signals[0].Count != 1 || sum.Signals[0].Strength != 1 {
+		t.Fatalf("unexpected summary: %+v", sum)
+	}
+}
@@ -0,0 +1,306 @@
+package gitdiff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backend reads commits, diffs and refs from a repository. ExecBackend runs
+// the git binary; GoGitBackend reads the repository directly and needs
+// neither git nor a shell.
+type Backend interface {
+	Name() string
+	TopLevel(repoPath string) (string, error)
+	UserName(repoPath string) (string, error)
+	Fetch(repoPath string) error
+	// Commits returns matching commits, newest first, with their changed files.
+	Commits(repoPath string, q CommitQuery) ([]Commit, error)
+	// RawDiff returns the zero-context patch for a commit, skipping excluded paths.
+	RawDiff(repoPath string, hash string, excludes []string) (string, error)
+	// CommitDays returns the distinct author dates (YYYY-MM-DD) since a time, newest first.
+	CommitDays(repoPath string, since time.Time) ([]string, error)
+	Graph(repoPath string, limit int) ([]GitGraphCommit, error)
+	// Authors returns author names of the most recent commits on HEAD.
+	Authors(repoPath string, limit int) ([]string, error)
+	// Branches returns, for each given commit, the normalized names of the
+	// local, remote and pull request branches that contain it.
+	Branches(repoPath string, hashes []string) (map[string][]string, error)
+	// BranchCommits returns, for each local, remote and pull request ref
+	// other than the default branches, the commits committed since a time
+	// that it holds and no default branch does, nearest its tip first, as
+	// full hashes. Keys are full ref names.
+	BranchCommits(repoPath string, since time.Time) (map[string][]string, error)
+	// WorkingTree returns uncommitted changes and stashes.
+	WorkingTree(repoPath string) (*WorkingTree, error)
+	// FileAt returns the content of a file at a revision, e.g. "abc1234"
+	// or "abc1234^".
+	FileAt(repoPath string, rev string, path string) (string, error)
+}
+
+// CommitQuery selects commits across all refs. Author and committer filters
+// must both match when both are set.
+type CommitQuery struct {
+	Authors    []string // case-insensitive substrings of "Name <email>", any of them
+	Committers []string // matched like Authors, against the committer
+	Since      time.Time
+	Until      time.Time
+	NoMerges   bool
+	MergesOnly bool
+	NoPatch    bool // skip file changes, e.g. for merge commits
+}
+
+var (
+	backendMu sync.RWMutex
+	backend   Backend = ExecBackend{}
+)
+
+// SetBackend replaces the backend used by the package functions.
+func SetBackend(b Backend) {
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	backend = b
+}
+
+// CurrentBackend returns the backend used by the package functions.
+func CurrentBackend() Backend {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return backend
+}
+
+// NewBackend returns the backend with the given config name: "exec" (the
+// default) or "go-git".
+func NewBackend(name string) (Backend, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "exec", "git":
+		return ExecBackend{}, nil
+	case "go-git", "gogit", "go":
+		return GoGitBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown git backend %q (use exec or go-git)", name)
+	}
+}
+
+// ExecBackend runs the git binary.
+type ExecBackend struct{}
+
+func (ExecBackend) Name() string { return "exec" }
+
+func (ExecBackend) TopLevel(repoPath string) (string, error) {
+	return TopLevel(repoPath)
+}
+
+func (ExecBackend) UserName(repoPath string) (string, error) {
+	return ConfigGet(repoPath, "user.name")
+}
+
+func (ExecBackend) Fetch(repoPath string) error {
+	_, err := Git(repoPath, "fetch", "--all")
+	return err
+}
+
+const gitTimeLayout = "2006-01-02 15:04:05"
+
+func (ExecBackend) Commits(repoPath string, q CommitQuery) ([]Commit, error) {
+	opts := LogOptions{
+		Authors:    q.Authors,
+		Committers: q.Committers,
+		IgnoreCase: true,
+		NoMerges:   q.NoMerges,
+		Merges:     q.MergesOnly,
+		Format:     logRecordFormat,
+		Patch:      !q.NoPatch,
+		Unified:    1,
+		Renames:    true,
+		All:        true,
+	}
+	if !q.Since.IsZero() {
+		opts.Since = q.Since.Format(gitTimeLayout)
+	}
+	if !q.Until.IsZero() {
+		opts.Until = q.Until.Format(gitTimeLayout)
+	}
+	raw, err := Log(repoPath, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGitLog(raw), nil
+}
+
+func (ExecBackend) RawDiff(repoPath string, hash string, excludes []string) (string, error) {
+	return Show(repoPath, ShowOptions{
+		Rev:            hash,
+		Unified:        0,
+		Minimal:        true,
+		IgnoreAllSpace: true,
+		Excludes:       excludes,
+	})
+}
+
+func (ExecBackend) CommitDays(repoPath string, since time.Time) ([]string, error) {
+	raw, err := Log(repoPath, LogOptions{
+		All:    true,
+		Format: "%ad",
+		Date:   "short",
+		Since:  since.Format(gitTimeLayout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uniqueDaysDesc(strings.Split(raw, "\n")), nil
+}
+
+func (ExecBackend) Graph(repoPath string, limit int) ([]GitGraphCommit, error) {
+	// %H: hash, %P: parents, %D: refs, %s: subject, %an: author, %at: date
+	raw, err := Log(repoPath, LogOptions{
+		All:      true,
+		Format:   "%H|%P|%D|%s|%an|%at",
+		MaxCount: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseGraphLog(raw), nil
+}
+
+func (ExecBackend) Authors(repoPath string, limit int) ([]string, error) {
+	raw, err := Log(repoPath, LogOptions{Format: "%an", MaxCount: limit})
+	if err != nil {
+		return nil, err
+	}
+	var authors []string
+	for _, line := range strings.Split(raw, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			authors = append(authors, line)
+		}
+	}
+	return authors, nil
+}
+
+func (ExecBackend) Branches(repoPath string, hashes []string) (map[string][]string, error) {
+	out := make(map[string][]string, len(hashes))
+	for _, hash := range hashes {
+		args := append([]string{"for-each-ref", "--contains", hash, "--format=%(refname)"}, branchRefPrefixes...)
+		raw, err := Git(repoPath, args...)
+		if err != nil {
+			return nil, err
+		}
+		out[hash] = normalizeBranches(strings.Split(raw, "\n"))
+	}
+	return out, nil
+}
+
+func (ExecBackend) BranchCommits(repoPath string, since time.Time) (map[string][]string, error) {
+	args := append([]string{"for-each-ref", "--format=%(refname) %(committerdate:unix)"}, branchRefPrefixes...)
+	raw, err := Git(repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+	var defaults, candidates []string
+	for _, line := range strings.Split(raw, "\n") {
+		ref, unix, ok := strings.Cut(strings.TrimSpace(line), " ")
+		name := normalizeBranch(ref)
+		if !ok || name == "" {
+			continue
+		}
+		if defaultBranches[name] {
+			defaults = append(defaults, ref)
+			continue
+		}
+		// A tip committed before since cannot hold a newer commit
+		if sec, err := strconv.ParseInt(unix, 10, 64); err == nil && time.Unix(sec, 0).Before(since) {
+			continue
+		}
+		candidates = append(candidates, ref)
+	}
+	out := make(map[string][]string, len(candidates))
+	for _, ref := range candidates {
+		args := []string{"rev-list", "--topo-order", "--since=" + since.Format(time.RFC3339), ref}
+		if len(defaults) > 0 {
+			args = append(append(args, "--not"), defaults...)
+		}
+		raw, err := Git(repoPath, args...)
+		if err != nil {
+			return nil, err
+		}
+		out[ref] = strings.Fields(raw)
+	}
+	return out, nil
+}
+
+func (ExecBackend) WorkingTree(repoPath string) (*WorkingTree, error) {
+	patch, err := Git(repoPath, "diff", "HEAD", "-U1")
+	if err != nil {
+		// No HEAD yet: everything staged is new
+		if patch, err = Git(repoPath, "diff", "--cached", "-U1"); err != nil {
+			return nil, err
+		}
+	}
+	wt := &WorkingTree{Files: parseFiles(strings.Split(patch, "\n"))}
+
+	untracked, err := Git(repoPath, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	wt.Files = append(wt.Files, untrackedFiles(strings.Split(untracked, "\n"))...)
+
+	list, err := Git(repoPath, "stash", "list", "--format=%gd%x1f%ct%x1f%gs")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(list, "\n") {
+		fields := strings.Split(line, fieldSep)
+		if len(fields) != 3 {
+			continue
+		}
+		stash := Stash{Ref: fields[0], At: parseUnix(fields[1]), Message: fields[2]}
+		patch, err := Git(repoPath, "stash", "show", "-p", "-U1", stash.Ref)
+		if err != nil {
+			return nil, err
+		}
+		stash.Files = parseFiles(strings.Split(patch, "\n"))
+		wt.Stashes = append(wt.Stashes, stash)
+	}
+	return wt, nil
+}
+
+func (ExecBackend) FileAt(repoPath string, rev string, path string) (string, error) {
+	if strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("invalid revision %q", rev)
+	}
+	return Git(repoPath, "show", rev+":"+path)
+}
+
+// untrackedFiles lists untracked paths as new files. Their content is not
+// read, so they only contribute new-file signals.
+func untrackedFiles(paths []string) []DiffFile {
+	var files []DiffFile
+	for _, p := range paths {
+		if p = strings.TrimSpace(p); p != "" {
+			files = append(files, DiffFile{Path: p, IsNew: true, IsTest: isTestFile(p)})
+		}
+	}
+	return files
+}
+
+func uniqueDaysDesc(days []string) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	for _, d := range days {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		if _, ok := seen[d]; ok {
+			continue
+		}
+		seen[d] = struct{}{}
+		out = append(out, d)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(out)))
+	return out
+}
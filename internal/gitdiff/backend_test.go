@@ -0,0 +1,213 @@
+package gitdiff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeAndCommit(t *testing.T, dir string, author string, when string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := Git(dir, "add", "-A"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_COMMITTER_DATE", when)
+	if _, err := Git(dir, "-c", "user.name="+author, "-c", "user.email="+strings.ToLower(author)+"@example.com",
+		"commit", "-q", "-m", "update "+when, "--date="+when); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackendsAgree(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+	writeAndCommit(t, dir, "Ana", "2026-02-05T10:00:00", map[string]string{
+		"main.go":             "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n",
+		"main_test.go":        "package main\n",
+		"node_modules/dep.js": "x\n",
+	})
+	if _, err := Git(dir, "checkout", "-q", "-b", "feature"); err != nil {
+		t.Fatal(err)
+	}
+	writeAndCommit(t, dir, "Bruno", "2026-02-05T15:00:00", map[string]string{
+		"docs/readme.md": "# hi\n",
+	})
+	if _, err := Git(dir, "tag", "v1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	execB, goB := ExecBackend{}, GoGitBackend{}
+
+	top1, err1 := execB.TopLevel(dir)
+	top2, err2 := goB.TopLevel(dir)
+	if err1 != nil || err2 != nil || filepath.Base(top1) != filepath.Base(top2) {
+		t.Fatalf("top level differs: %q %v / %q %v", top1, err1, top2, err2)
+	}
+
+	day, _ := time.ParseInLocation("2006-01-02", "2026-02-05", time.Local)
+	q := CommitQuery{Authors: []string{"ana"}, Since: day, Until: day.Add(24*time.Hour - time.Second), NoMerges: true}
+	c1, err1 := execB.Commits(dir, q)
+	c2, err2 := goB.Commits(dir, q)
+	if err1 != nil || err2 != nil {
+		t.Fatal(err1, err2)
+	}
+	if len(c1) != 1 || !reflect.DeepEqual(c1, c2) {
+		t.Fatalf("commits differ:\nexec:   %+v\ngo-git: %+v", c1, c2)
+	}
+
+	d1, _ := execB.RawDiff(dir, c1[0].Hash, []string{"node_modules"})
+	d2, err := goB.RawDiff(dir, c2[0].Hash, []string{"node_modules"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{d1, d2} {
+		if !strings.Contains(d, "main.go") || !strings.Contains(d, `+	println("hi")`) || strings.Contains(d, "node_modules") {
+			t.Fatalf("unexpected raw diff:\n%s", d)
+		}
+	}
+
+	since := day.AddDate(0, 0, -3)
+	days1, _ := execB.CommitDays(dir, since)
+	days2, _ := goB.CommitDays(dir, since)
+	if !reflect.DeepEqual(days1, []string{"2026-02-05", "2026-02-04"}) || !reflect.DeepEqual(days1, days2) {
+		t.Fatalf("commit days differ: %v / %v", days1, days2)
+	}
+
+	g1, _ := execB.Graph(dir, 10)
+	g2, _ := goB.Graph(dir, 10)
+	if len(g1) != 3 || len(g1) != len(g2) {
+		t.Fatalf("graph sizes differ: %d / %d", len(g1), len(g2))
+	}
+	for i := range g1 {
+		if g1[i].Hash != g2[i].Hash || !reflect.DeepEqual(g1[i].Parents, g2[i].Parents) || g1[i].Author != g2[i].Author || g1[i].Date != g2[i].Date {
+			t.Fatalf("graph commit %d differs: %+v / %+v", i, g1[i], g2[i])
+		}
+	}
+	if refs := strings.Join(g2[0].Refs, ","); !strings.Contains(refs, "feature") || !strings.Contains(refs, "v1.0") {
+		t.Fatalf("expected branch and tag refs on tip, got %v", g2[0].Refs)
+	}
+
+	a1, _ := execB.Authors(dir, 2)
+	a2, _ := goB.Authors(dir, 2)
+	if !reflect.DeepEqual(a1, []string{"Bruno", "Ana"}) || !reflect.DeepEqual(a1, a2) {
+		t.Fatalf("authors differ: %v / %v", a1, a2)
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	for name, want := range map[string]string{"": "exec", "exec": "exec", "go-git": "go-git"} {
+		b, err := NewBackend(name)
+		if err != nil || b.Name() != want {
+			t.Fatalf("NewBackend(%q) = %v, %v", name, b, err)
+		}
+	}
+	if _, err := NewBackend("libgit2"); err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+}
+
+func TestBackendsParseCommitBodies(t *testing.T) {
+	dir := initTestRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Git(dir, "add", "a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	msg := "Add a\n\ncommit 1234567 was reverted earlier\n\nCo-authored-by: Bruno <bruno@example.com>"
+	if _, err := Git(dir, "-c", "user.name=Ana", "-c", "user.email=ana@example.com", "commit", "-q", "-m", msg); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range []Backend{ExecBackend{}, GoGitBackend{}} {
+		commits, err := b.Commits(dir, CommitQuery{Authors: []string{"ana"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(commits) != 1 {
+			t.Fatalf("%s: expected 1 commit, got %+v", b.Name(), commits)
+		}
+		c := commits[0]
+		if c.Body != "commit 1234567 was reverted earlier\n\nCo-authored-by: Bruno <bruno@example.com>" ||
+			len(c.CoAuthors) != 1 || c.CoAuthors[0].Email != "bruno@example.com" || len(c.Files) != 1 {
+			t.Fatalf("%s: unexpected commit %+v", b.Name(), c)
+		}
+	}
+}
+
+func TestBackendsAgreeOnHunks(t *testing.T) {
+	dir := initTestRepo(t)
+	service := []string{
+		"package orders", "", "func Create(id int) error {", "\tif id == 0 {", "\t\treturn nil", "\t}",
+		"\tsave(id)", "\treturn nil", "}", "", "func Cancel(id int) error {", "\tlookup(id)",
+		"\tremove(id)", "\tnotify(id)", "\treturn nil", "}",
+	}
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{
+		"service.go": strings.Join(service, "\n") + "\n",
+		"notes.txt":  "one\ntwo\nthree\nfour\nfive\n",
+		"util.txt":   "alpha\nbeta\ngamma\ndelta\nepsilon\nzeta\neta\ntheta\n",
+		"logo.bin":   "\x00\x01\x02",
+	})
+	service[4] = "\t\treturn ErrMissingID"
+	service[6] = "\tsaveAll(id)"
+	service = append(service[:13], append([]string{"\taudit(id)"}, service[13:]...)...)
+	for _, name := range []string{"notes.txt", "util.txt"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeAndCommit(t, dir, "Ana", "2026-02-05T10:00:00", map[string]string{
+		"service.go":     strings.Join(service, "\n") + "\n",
+		"docs/notes.txt": "one\ntwo\nthree\nfour\nfive\n",
+		"pkg/util.txt":   "alpha\nbeta\ngamma\ndelta\nepsilon\nzeta\neta\niota\n",
+		"logo.bin":       "\x00\x01\x03",
+	})
+
+	q := CommitQuery{Authors: []string{"ana"}, NoMerges: true}
+	c1, err1 := ExecBackend{}.Commits(dir, q)
+	c2, err2 := GoGitBackend{}.Commits(dir, q)
+	if err1 != nil || err2 != nil {
+		t.Fatal(err1, err2)
+	}
+	if len(c1) != 2 || !reflect.DeepEqual(c1[0].Files, c2[0].Files) {
+		t.Fatalf("files differ:\nexec:   %+v\ngo-git: %+v", c1[0].Files, c2[0].Files)
+	}
+
+	files := make(map[string]DiffFile)
+	for _, f := range c1[0].Files {
+		files[f.Path] = f
+	}
+	if f, ok := files["docs/notes.txt"]; !ok || f.IsNew || !f.IsRename || f.OldPath != "notes.txt" || f.Similarity != 100 || len(f.Hunks) != 0 {
+		t.Fatalf("expected rename without changes, got %+v", c1[0].Files)
+	}
+	if f := files["pkg/util.txt"]; !f.IsRename || f.OldPath != "util.txt" || f.Similarity != 86 || len(f.Hunks) != 1 {
+		t.Fatalf("expected rename with edits, got %+v", f)
+	}
+	if !files["logo.bin"].IsBinary {
+		t.Fatalf("expected binary flag, got %+v", files["logo.bin"])
+	}
+	hunks := files["service.go"].Hunks
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %+v", hunks)
+	}
+	if h := hunks[0]; h.OldStart != 4 || h.NewStart != 4 || h.Section != "func Create(id int) error {" || h.Edit() != EditModified {
+		t.Fatalf("unexpected first hunk: %+v", h)
+	}
+	if h := hunks[1]; h.NewStart != 13 || h.NewLines != 3 || h.Section != "func Cancel(id int) error {" || h.Edit() != EditAdded {
+		t.Fatalf("unexpected second hunk: %+v", h)
+	}
+}
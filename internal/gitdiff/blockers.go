@@ -0,0 +1,73 @@
+package gitdiff
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Blocker is an impediment recorded against a repo. It is carried into every
+// report from OpenedOn onwards until ResolvedOn is set.
+type Blocker struct {
+	ID          int64  `json:"id,omitempty"`
+	Description string `json:"description"`
+	Owner       string `json:"owner,omitempty"`
+	OpenedOn    string `json:"opened_on"`             // YYYY-MM-DD
+	ResolvedOn  string `json:"resolved_on,omitempty"` // YYYY-MM-DD
+}
+
+// IsOpenOn reports whether the blocker should appear in the report for date.
+func (b Blocker) IsOpenOn(date string) bool {
+	day := ISODate(date)
+	opened := ISODate(b.OpenedOn)
+	if opened != "" && opened > day {
+		return false
+	}
+	resolved := ISODate(b.ResolvedOn)
+	return resolved == "" || resolved > day
+}
+
+// AgeDays returns how many days the blocker has been open as of date.
+func (b Blocker) AgeDays(date string) int {
+	opened, err := time.Parse("2006-01-02", ISODate(b.OpenedOn))
+	if err != nil {
+		return 0
+	}
+	day, err := time.Parse("2006-01-02", ISODate(date))
+	if err != nil || day.Before(opened) {
+		return 0
+	}
+	return int(day.Sub(opened).Hours() / 24)
+}
+
+var (
+	blockerClauseRe = regexp.MustCompile(`(?i)\bblock(?:er|ed)\s*:\s*([^;\n]+);?`)
+	blockerOwnerRe  = regexp.MustCompile(`@([\w.\-]+)`)
+)
+
+// ParseBlockers pulls "blocker: <text> @owner" clauses out of the extra context
+// passed on the command line. Clauses end at ";" or a newline. The remaining
+// context is returned so the LLM stages do not turn blockers into tasks.
+func ParseBlockers(extra string, date string) ([]Blocker, string) {
+	var blockers []Blocker
+	for _, m := range blockerClauseRe.FindAllStringSubmatch(extra, -1) {
+		desc := m[1]
+		owner := ""
+		if om := blockerOwnerRe.FindStringSubmatch(desc); len(om) > 1 {
+			owner = om[1]
+			desc = blockerOwnerRe.ReplaceAllString(desc, "")
+		}
+		desc = strings.Join(strings.Fields(desc), " ")
+		desc = strings.TrimRight(desc, " .,")
+		if desc == "" {
+			continue
+		}
+		blockers = append(blockers, Blocker{
+			Description: desc,
+			Owner:       owner,
+			OpenedOn:    ISODate(date),
+		})
+	}
+	rest := blockerClauseRe.ReplaceAllString(extra, "")
+	return blockers, strings.TrimSpace(rest)
+}
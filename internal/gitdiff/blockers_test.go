@@ -0,0 +1,27 @@
+package gitdiff
+
+import "testing"
+
+func TestParseBlockersExtractsClausesAndOwner(t *testing.T) {
+	blockers, rest := ParseBlockers("fixed checkout retries; blocker: waiting on staging DB creds @carlos; pairing with Ana", "02-05-2026")
+	if len(blockers) != 1 {
+		t.Fatalf("expected 1 blocker, got %#v", blockers)
+	}
+	b := blockers[0]
+	if b.Description != "waiting on staging DB creds" || b.Owner != "carlos" || b.OpenedOn != "2026-02-05" {
+		t.Fatalf("unexpected blocker: %#v", b)
+	}
+	if rest != "fixed checkout retries;  pairing with Ana" {
+		t.Fatalf("unexpected remaining context: %q", rest)
+	}
+}
+
+func TestBlockerCarriedForwardUntilResolved(t *testing.T) {
+	b := Blocker{Description: "x", OpenedOn: "2026-02-03", ResolvedOn: "2026-02-06"}
+	if b.IsOpenOn("2026-02-02") || !b.IsOpenOn("2026-02-05") || b.IsOpenOn("2026-02-06") {
+		t.Fatalf("unexpected open range for %#v", b)
+	}
+	if age := b.AgeDays("02-05-2026"); age != 2 {
+		t.Fatalf("expected age 2, got %d", age)
+	}
+}
@@ -0,0 +1,156 @@
+package gitdiff
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// defaultBranches are long-lived branches that say nothing about the task a
+// commit belongs to.
+var defaultBranches = map[string]bool{
+	"main": true, "master": true, "develop": true, "development": true,
+	"dev": true, "trunk": true, "staging": true, "production": true,
+}
+
+// branchRefPrefixes are the refs searched for branch membership. GitHub pull
+// request heads are included when they have been fetched.
+var branchRefPrefixes = []string{"refs/heads/", "refs/remotes/", "refs/pull/"}
+
+// normalizeBranch turns a full ref name into the branch name shown in
+// reports: "refs/remotes/origin/feat/x" becomes "feat/x" and
+// "refs/pull/12/head" becomes "pull/12". It returns "" for refs that are not
+// branches, such as remote HEADs.
+func normalizeBranch(ref string) string {
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		return strings.TrimPrefix(ref, "refs/heads/")
+	case strings.HasPrefix(ref, "refs/remotes/"):
+		_, name, ok := strings.Cut(strings.TrimPrefix(ref, "refs/remotes/"), "/")
+		if !ok || name == "HEAD" {
+			return ""
+		}
+		return name
+	case strings.HasPrefix(ref, "refs/pull/"):
+		number, kind, _ := strings.Cut(strings.TrimPrefix(ref, "refs/pull/"), "/")
+		if kind != "head" {
+			return ""
+		}
+		return "pull/" + number
+	}
+	return ""
+}
+
+// normalizeBranches normalizes, deduplicates and sorts ref names.
+func normalizeBranches(refs []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, ref := range refs {
+		name := normalizeBranch(strings.TrimSpace(ref))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// FeatureBranch picks the branch a commit was most likely written on, from
+// the branch commits returned by Backend.BranchCommits. Only branches on
+// which no default branch contains the commit count, so a branch forked
+// later from one holding the commit does not claim it. Among those, the tip
+// closest to the commit wins, then named branches over pull request heads.
+// It returns "" for commits on a default branch.
+func FeatureBranch(hash string, branchCommits map[string][]string) string {
+	best, bestDist := "", -1
+	for ref, hashes := range branchCommits {
+		name := normalizeBranch(ref)
+		if name == "" || defaultBranches[name] || hash == "" {
+			continue
+		}
+		dist := slices.IndexFunc(hashes, func(h string) bool { return strings.HasPrefix(h, hash) })
+		if dist < 0 {
+			continue
+		}
+		if best == "" || closerBranch(name, dist, best, bestDist) {
+			best, bestDist = name, dist
+		}
+	}
+	return best
+}
+
+// closerBranch reports whether branch a, dist commits from the commit, is a
+// better pick than branch b.
+func closerBranch(a string, distA int, b string, distB int) bool {
+	if distA != distB {
+		return distA < distB
+	}
+	if pullA, pullB := strings.HasPrefix(a, "pull/"), strings.HasPrefix(b, "pull/"); pullA != pullB {
+		return pullB
+	}
+	return a < b
+}
+
+var branchTaskTypes = map[string]string{
+	"feat": "feature", "feature": "feature",
+	"fix": "bugfix", "bugfix": "bugfix", "hotfix": "bugfix",
+	"chore": "chore", "refactor": "refactor", "docs": "docs",
+	"test": "test", "tests": "test", "perf": "performance",
+}
+
+// BranchTask describes the task seeded for a feature branch: a readable
+// intent and a task type derived from a conventional prefix such as "feat/".
+func BranchTask(branch string) (intent string, taskType string) {
+	name := branch
+	taskType = "feature"
+	if prefix, rest, ok := strings.Cut(branch, "/"); ok {
+		if t, known := branchTaskTypes[strings.ToLower(prefix)]; known {
+			taskType = t
+			name = rest
+		}
+	}
+	if strings.HasPrefix(branch, "pull/") {
+		return "pull request #" + strings.TrimPrefix(branch, "pull/"), taskType
+	}
+	name = strings.NewReplacer("-", " ", "_", " ", "/", " ").Replace(name)
+	return strings.Join(strings.Fields(name), " "), taskType
+}
+
+// SeedBranchTasks pre-groups commits by feature branch before the LLM
+// incorporation stage. Commits go to the existing task for their branch, or
+// to a new task per branch; commits already referenced by a task and commits
+// on default branches are left for the LLM.
+func SeedBranchTasks(tasks []TaskChange, semantics []CommitSemantic) []TaskChange {
+	linked := make(map[string]bool)
+	byBranch := make(map[string]int)
+	for i, t := range tasks {
+		for _, c := range t.Commits {
+			linked[shortHash(c)] = true
+		}
+		if t.Branch != "" && !t.IsManual {
+			byBranch[t.Branch] = i
+		}
+	}
+	for _, s := range semantics {
+		if s.Branch == "" || linked[s.CommitHash] {
+			continue
+		}
+		idx, ok := byBranch[s.Branch]
+		if !ok {
+			intent, taskType := BranchTask(s.Branch)
+			tasks = append(tasks, TaskChange{
+				TaskType:   taskType,
+				TaskIntent: intent,
+				Scope:      s.Branch,
+				Branch:     s.Branch,
+			})
+			idx = len(tasks) - 1
+			byBranch[s.Branch] = idx
+		}
+		tasks[idx].Commits = append(tasks[idx].Commits, s.CommitHash)
+		linked[s.CommitHash] = true
+	}
+	return tasks
+}
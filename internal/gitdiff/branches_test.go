@@ -0,0 +1,146 @@
+package gitdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackendsAgreeOnBranches(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{"a.txt": "a\n"})
+	if _, err := Git(dir, "branch", "-M", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Git(dir, "checkout", "-q", "-b", "feat/checkout-retry"); err != nil {
+		t.Fatal(err)
+	}
+	writeAndCommit(t, dir, "Ana", "2026-02-05T10:00:00", map[string]string{"b.txt": "b\n"})
+	tip, err := Git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tip = strings.TrimSpace(tip)
+	for _, ref := range []string{"refs/remotes/origin/feat/checkout-retry", "refs/pull/12/head"} {
+		if _, err := Git(dir, "update-ref", ref, tip); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base, _ := Git(dir, "rev-parse", "--short=7", "main")
+	base = strings.TrimSpace(base)
+	feature := tip[:7]
+
+	want := map[string][]string{
+		base:    {"feat/checkout-retry", "main", "pull/12"},
+		feature: {"feat/checkout-retry", "pull/12"},
+	}
+	for _, b := range []Backend{ExecBackend{}, GoGitBackend{}} {
+		got, err := b.Branches(dir, []string{base, feature})
+		if err != nil {
+			t.Fatalf("%s: %v", b.Name(), err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: unexpected branches %v", b.Name(), got)
+		}
+	}
+}
+
+func TestBackendsAttributeSharedCommitToClosestBranch(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{"a.txt": "a\n"})
+	if _, err := Git(dir, "branch", "-M", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Git(dir, "checkout", "-q", "-b", "feat/checkout-retry"); err != nil {
+		t.Fatal(err)
+	}
+	writeAndCommit(t, dir, "Ana", "2026-02-05T10:00:00", map[string]string{"b.txt": "b\n"})
+	shared, _ := Git(dir, "rev-parse", "HEAD")
+	shared = strings.TrimSpace(shared)
+	// A colleague forks from the feature branch and builds on it; an
+	// alphabetically earlier branch now holds the commit too.
+	if _, err := Git(dir, "checkout", "-q", "-b", "colleague/api"); err != nil {
+		t.Fatal(err)
+	}
+	writeAndCommit(t, dir, "Bea", "2026-02-05T11:00:00", map[string]string{"c.txt": "c\n"})
+	theirs, _ := Git(dir, "rev-parse", "HEAD")
+	theirs = strings.TrimSpace(theirs)
+	if _, err := Git(dir, "update-ref", "refs/remotes/origin/colleague/api", theirs); err != nil {
+		t.Fatal(err)
+	}
+	base, _ := Git(dir, "rev-parse", "main")
+	base = strings.TrimSpace(base)
+
+	since := time.Date(2026, 2, 4, 0, 0, 0, 0, time.Local)
+	for _, b := range []Backend{ExecBackend{}, GoGitBackend{}} {
+		got, err := b.BranchCommits(dir, since)
+		if err != nil {
+			t.Fatalf("%s: %v", b.Name(), err)
+		}
+		if branch := FeatureBranch(shared[:7], got); branch != "feat/checkout-retry" {
+			t.Fatalf("%s: shared commit attributed to %q (%v)", b.Name(), branch, got)
+		}
+		if branch := FeatureBranch(theirs[:7], got); branch != "colleague/api" {
+			t.Fatalf("%s: colleague commit attributed to %q", b.Name(), branch)
+		}
+		if branch := FeatureBranch(base[:7], got); branch != "" {
+			t.Fatalf("%s: default branch commit attributed to %q", b.Name(), branch)
+		}
+	}
+}
+
+func TestFeatureBranch(t *testing.T) {
+	branchCommits := map[string][]string{
+		"refs/heads/feat/checkout-retry":          {"ccc3333", "aaa1111"},
+		"refs/remotes/origin/feat/checkout-retry": {"ccc3333", "aaa1111"},
+		"refs/remotes/origin/fix/cart":            {"ddd4444", "eee5555", "ccc3333", "aaa1111"},
+		"refs/pull/12/head":                       {"bbb2222"},
+		"refs/heads/zz/retry":                     {"bbb2222"},
+		"refs/remotes/origin/HEAD":                {"fff6666"},
+	}
+	cases := map[string]string{
+		"aaa1111": "feat/checkout-retry",
+		"ccc3333": "feat/checkout-retry",
+		"ddd4444": "fix/cart",
+		"bbb2222": "zz/retry",
+		"fff6666": "",
+		"9999999": "",
+	}
+	for hash, want := range cases {
+		if got := FeatureBranch(hash, branchCommits); got != want {
+			t.Fatalf("FeatureBranch(%s) = %q, want %q", hash, got, want)
+		}
+	}
+	if intent, taskType := BranchTask("fix/PROJ-12_null-cart"); intent != "PROJ 12 null cart" || taskType != "bugfix" {
+		t.Fatalf("unexpected branch task: %q %q", intent, taskType)
+	}
+}
+
+func TestSeedBranchTasks(t *testing.T) {
+	tasks := []TaskChange{
+		{TaskIntent: "checkout retry", Branch: "feat/checkout-retry", Commits: []string{"aaaaaaa"}},
+		{TaskIntent: "old work", Commits: []string{"ccccccc"}},
+	}
+	semantics := []CommitSemantic{
+		{CommitHash: "aaaaaaa", Branch: "feat/checkout-retry"},
+		{CommitHash: "bbbbbbb", Branch: "feat/checkout-retry"},
+		{CommitHash: "ccccccc", Branch: "fix/cart"},
+		{CommitHash: "ddddddd", Branch: "fix/cart"},
+		{CommitHash: "eeeeeee"},
+	}
+	got := SeedBranchTasks(tasks, semantics)
+	if len(got) != 3 {
+		t.Fatalf("expected one new branch task, got %+v", got)
+	}
+	if !reflect.DeepEqual(got[0].Commits, []string{"aaaaaaa", "bbbbbbb"}) {
+		t.Fatalf("expected commit added to existing branch task, got %v", got[0].Commits)
+	}
+	seed := got[2]
+	if seed.Branch != "fix/cart" || seed.TaskType != "bugfix" || seed.TaskIntent != "cart" || !reflect.DeepEqual(seed.Commits, []string{"ddddddd"}) {
+		t.Fatalf("unexpected seeded task: %+v", seed)
+	}
+	if seed.TechnicalWhy != "" {
+		t.Fatalf("expected the analysis left to the model, got %q", seed.TechnicalWhy)
+	}
+}
@@ -0,0 +1,221 @@
+package gitdiff
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"md2slack/internal/hintdetector"
+)
+
+// Test coverage signals, added per commit once every file is known, see
+// ApplyTestCoverage.
+const (
+	SignalTestedChange   SignalType = "tested_change"
+	SignalUntestedChange SignalType = "untested_change"
+)
+
+// untestedWeight keeps a broad untested edit from outranking what the
+// commit actually does; it is a reminder, not the change itself.
+const untestedWeight = 0.5
+
+// testDirs are directory names that hold only tests.
+var testDirs = []string{"test", "tests", "__tests__", "spec", "specs", "testdata", "e2e"}
+
+// isTestFile reports whether a path is a test, by the naming conventions of
+// Go, JS/TS, Python, Java/Kotlin and Ruby or a test directory.
+func isTestFile(p string) bool {
+	for _, dir := range strings.Split(strings.ToLower(path.Dir(p)), "/") {
+		if slices.Contains(testDirs, dir) {
+			return true
+		}
+	}
+	base := path.Base(p)
+	ext := strings.ToLower(path.Ext(base))
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	switch ext {
+	case ".go":
+		return strings.HasSuffix(stem, "_test")
+	case ".py":
+		return strings.HasPrefix(stem, "test_") || strings.HasSuffix(stem, "_test") || stem == "conftest"
+	case ".rb":
+		return strings.HasSuffix(stem, "_spec") || strings.HasSuffix(stem, "_test")
+	case ".java", ".kt", ".scala":
+		// Class names: CartTest, CartTests, CartSpec, CartIT
+		return strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests") || strings.HasSuffix(stem, "Spec") || strings.HasSuffix(stem, "IT")
+	}
+	return strings.Contains(base, ".test.") || strings.Contains(base, ".spec.")
+}
+
+// testSubject returns the name of the source file a test exercises without
+// its extension, e.g. "cart" for cart_test.go, test_cart.py, Cart.test.tsx
+// and CartTest.java.
+func testSubject(p string) string {
+	base := path.Base(p)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if i := strings.Index(stem, ".test"); i > 0 {
+		stem = stem[:i]
+	} else if i := strings.Index(stem, ".spec"); i > 0 {
+		stem = stem[:i]
+	}
+	stem = strings.TrimPrefix(stem, "test_")
+	for _, suffix := range []string{"_test", "_spec", "Tests", "Test", "Spec", "IT"} {
+		if s := strings.TrimSuffix(stem, suffix); s != stem && s != "" {
+			stem = s
+			break
+		}
+	}
+	return strings.ToLower(stem)
+}
+
+func sourceStem(p string) string {
+	base := path.Base(p)
+	return strings.ToLower(strings.TrimSuffix(base, path.Ext(base)))
+}
+
+// needsTests reports whether a changed file is code that tests could cover:
+// not a test, not deleted, with added lines, in a programming language.
+func needsTests(f DiffFile) bool {
+	if f.IsTest || f.IsDeleted || f.IsBinary || f.IsRename && f.Similarity == 100 || len(f.Additions) == 0 {
+		return false
+	}
+	switch hintdetector.Language(f.Path) {
+	case "", "sql", "css", "html", "vue", "svelte":
+		return false
+	}
+	return true
+}
+
+// testLanguage groups JavaScript and TypeScript, which test each other.
+func testLanguage(p string) string {
+	if l := hintdetector.Language(p); l != "typescript" {
+		return l
+	}
+	return "javascript"
+}
+
+// TestedSources maps each test file of a change to the changed source files
+// it exercises: the ones in its language named like it, or for Go, failing
+// that, the package's files in the same directory.
+func TestedSources(files []DiffFile) map[string][]string {
+	out := make(map[string][]string)
+	for _, t := range files {
+		if !t.IsTest || t.IsDeleted {
+			continue
+		}
+		subject := testSubject(t.Path)
+		var byName, byPackage []string
+		for _, f := range files {
+			if !needsTests(f) || testLanguage(f.Path) != testLanguage(t.Path) {
+				continue
+			}
+			if sourceStem(f.Path) == subject {
+				byName = append(byName, f.Path)
+			} else if path.Ext(t.Path) == ".go" && path.Ext(f.Path) == ".go" && path.Dir(f.Path) == path.Dir(t.Path) {
+				byPackage = append(byPackage, f.Path)
+			}
+		}
+		if len(byName) > 0 {
+			out[t.Path] = byName
+		} else if len(byPackage) > 0 {
+			out[t.Path] = byPackage
+		}
+	}
+	return out
+}
+
+// ApplyTestCoverage adds the coverage signals of a change to the signals
+// of its files, which signals lists in the same order. A test file says
+// which sources it covers ("added tests for cart.go"); a source file is
+// either tested_change or, when no test in the change covers it,
+// untested_change. Files that gain a signal get one even if they had none.
+func ApplyTestCoverage(files []DiffFile, signals []Signal) {
+	tested := TestedSources(files)
+	covered := make(map[string]bool)
+	for _, sources := range tested {
+		for _, src := range sources {
+			covered[src] = true
+		}
+	}
+
+	for i, f := range files {
+		s := &signals[i]
+		if s.File == "" {
+			s.File = f.Path
+		}
+		switch {
+		case f.IsTest && !f.IsDeleted:
+			verb := "updated"
+			if f.IsNew {
+				verb = "added"
+			}
+			subjects := tested[f.Path]
+			if len(subjects) == 0 {
+				subjects = []string{testSubject(f.Path)}
+			}
+			for _, subject := range subjects {
+				s.addHint(verb + " tests for " + subject)
+			}
+		case needsTests(f) && covered[f.Path]:
+			s.add(SignalTestedChange, structuralWeight)
+		case needsTests(f):
+			s.add(SignalUntestedChange, untestedWeight)
+			s.addHint("changed " + f.Path + " without tests")
+		}
+	}
+}
+
+func (s *Signal) add(t SignalType, weight float64) {
+	if !slices.Contains(s.Types, t) {
+		s.Types = append(s.Types, t)
+	}
+	if s.Weights == nil {
+		s.Weights = make(map[SignalType]float64)
+		s.Counts = make(map[SignalType]int)
+	}
+	s.Weights[t] = max(s.Weights[t], weight)
+	s.Counts[t]++
+}
+
+func (s *Signal) addHint(h string) {
+	if !slices.Contains(s.Hints, h) {
+		s.Hints = append(s.Hints, h)
+	}
+}
+
+// UntestedFiles returns the files of a commit's signals that changed without
+// tests.
+func (c CommitSemantic) UntestedFiles() []string {
+	var out []string
+	for _, s := range c.Signals {
+		if slices.Contains(s.Types, SignalUntestedChange) {
+			out = append(out, s.File)
+		}
+	}
+	return out
+}
+
+// ApplyUntested sets each commit task's untested files to those of its
+// commits. Manual tasks are left alone.
+func ApplyUntested(tasks []TaskChange, semantics []CommitSemantic) []TaskChange {
+	byCommit := make(map[string][]string)
+	for _, sem := range semantics {
+		byCommit[shortHash(sem.CommitHash)] = sem.UntestedFiles()
+	}
+	for i := range tasks {
+		if tasks[i].IsManual {
+			continue
+		}
+		var files []string
+		for _, hash := range tasks[i].Commits {
+			for _, f := range byCommit[shortHash(hash)] {
+				if !slices.Contains(files, f) {
+					files = append(files, f)
+				}
+			}
+		}
+		tasks[i].UntestedFiles = files
+	}
+	return tasks
+}
@@ -0,0 +1,95 @@
+package gitdiff
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestIsTestFile(t *testing.T) {
+	tests := map[string]bool{
+		"internal/cart/cart_test.go":            true,
+		"shop/test_orders.py":                   true,
+		"shop/orders_test.py":                   true,
+		"web/src/__tests__/Cart.tsx":            true,
+		"web/src/Cart.test.tsx":                 true,
+		"web/src/cart.spec.ts":                  true,
+		"src/test/java/com/acme/CartTest.java":  true,
+		"src/main/java/com/acme/CartTests.java": true,
+		"spec/models/user_spec.rb":              true,
+		"tests/e2e/checkout.py":                 true,
+		"internal/cart/cart.go":                 false,
+		"src/main/java/com/acme/Latest.java":    false,
+		"web/src/testUtils.ts":                  false,
+		"shop/contest.py":                       false,
+		"docs/testing.md":                       false,
+	}
+	for path, want := range tests {
+		if got := isTestFile(path); got != want {
+			t.Errorf("isTestFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestTestedSources(t *testing.T) {
+	files := []DiffFile{
+		{Path: "cart/cart.go", Additions: []string{"x"}},
+		{Path: "cart/price.go", Additions: []string{"x"}},
+		{Path: "cart/price_test.go", IsTest: true},
+		{Path: "cart/cart_internal_test.go", IsTest: true},
+		{Path: "web/src/Checkout.tsx", Additions: []string{"x"}},
+		{Path: "web/src/__tests__/Checkout.test.tsx", IsTest: true, IsNew: true},
+		{Path: "src/main/java/com/acme/Cart.java", Additions: []string{"x"}},
+		{Path: "src/test/java/com/acme/CartTest.java", IsTest: true},
+		{Path: "shop/test_orders.py", IsTest: true},
+	}
+	want := map[string][]string{
+		"cart/price_test.go":                   {"cart/price.go"},
+		"cart/cart_internal_test.go":           {"cart/cart.go", "cart/price.go"},
+		"web/src/__tests__/Checkout.test.tsx":  {"web/src/Checkout.tsx"},
+		"src/test/java/com/acme/CartTest.java": {"src/main/java/com/acme/Cart.java"},
+	}
+	if got := TestedSources(files); !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected mapping:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestApplyTestCoverage(t *testing.T) {
+	files := []DiffFile{
+		{Path: "billing/invoice.go", Additions: []string{"x"}},
+		{Path: "billing/invoice_test.go", IsTest: true, IsNew: true},
+		{Path: "api/handler.ts", Additions: []string{"x"}},
+		{Path: "README.md", Additions: []string{"x"}},
+		{Path: "shop/test_orders.py", IsTest: true},
+	}
+	signals := make([]Signal, len(files))
+	for i, f := range files {
+		signals[i] = ExtractSignals(f)
+	}
+	ApplyTestCoverage(files, signals)
+
+	if !slices.Contains(signals[0].Types, SignalTestedChange) || slices.Contains(signals[0].Types, SignalUntestedChange) {
+		t.Errorf("expected invoice.go to be tested: %+v", signals[0])
+	}
+	if !slices.Contains(signals[1].Hints, "added tests for billing/invoice.go") {
+		t.Errorf("missing test hint: %+v", signals[1])
+	}
+	if !slices.Contains(signals[2].Types, SignalUntestedChange) || !slices.Contains(signals[2].Hints, "changed api/handler.ts without tests") || signals[2].Weights[SignalUntestedChange] != untestedWeight {
+		t.Errorf("expected handler.ts to be untested: %+v", signals[2])
+	}
+	if len(signals[3].Types) != 0 {
+		t.Errorf("docs need no tests: %+v", signals[3])
+	}
+	if !slices.Contains(signals[4].Hints, "updated tests for orders") {
+		t.Errorf("missing test hint: %+v", signals[4])
+	}
+
+	sem := CommitSemantic{CommitHash: "abc1234", Signals: signals}
+	tasks := ApplyUntested([]TaskChange{
+		{TaskIntent: "bill invoices", Commits: []string{"abc1234def"}},
+		{TaskIntent: "pair", IsManual: true, UntestedFiles: []string{"kept.go"}},
+	}, []CommitSemantic{sem})
+	if !reflect.DeepEqual(tasks[0].UntestedFiles, []string{"api/handler.ts"}) || !reflect.DeepEqual(tasks[1].UntestedFiles, []string{"kept.go"}) {
+		t.Fatalf("unexpected untested files: %+v", tasks)
+	}
+}
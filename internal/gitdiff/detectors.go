@@ -0,0 +1,88 @@
+package gitdiff
+
+import (
+	"path/filepath"
+	"sync"
+
+	"md2slack/internal/hintdetector"
+)
+
+// DetectorSettings choose the line detectors each repository runs. Repos
+// maps are keyed by repository name, like the other per-repo settings.
+type DetectorSettings struct {
+	// Auto skips framework detectors, such as nextjs or drizzle, in
+	// repositories whose manifests do not use the framework.
+	Auto bool
+	// Disable and Enable list detector names for every repository.
+	// Disable wins; Enable keeps a detector Auto would skip.
+	Disable     []string
+	Enable      []string
+	RepoDisable map[string][]string
+	RepoEnable  map[string][]string
+	// Weights override detector weights by name.
+	Weights map[string]float64
+}
+
+var (
+	detectorsMu   sync.RWMutex
+	detectorCfg   = DetectorSettings{Auto: true}
+	userDetectors []hintdetector.Spec
+)
+
+// SetDetectorSettings replaces the global detector settings used by
+// DetectorsFor.
+func SetDetectorSettings(s DetectorSettings) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	detectorCfg = s
+}
+
+// SetUserDetectors replaces the user-defined detectors, which run after the
+// built-in ones.
+func SetUserDetectors(specs []hintdetector.Spec) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	userDetectors = specs
+}
+
+// LoadUserDetectors reads the detector files in ~/.md2slack/detectors again,
+// so edits apply to the next report without a rebuild or restart. Invalid
+// files and rules are skipped and returned as the error.
+func LoadUserDetectors() error {
+	specs, err := hintdetector.LoadRules(hintdetector.UserRulesDir())
+	SetUserDetectors(specs)
+	return err
+}
+
+// DetectorsFor returns the built-in and user detectors a repository runs,
+// with its disabled detectors removed and weights applied. Framework
+// detectors are auto-enabled from the stack found in the repository's
+// manifests. Outside a repository, every detector not disabled globally
+// runs.
+func DetectorsFor(repoPath string) []hintdetector.Spec {
+	specs, cfg := currentDetectors()
+	sel := hintdetector.Selection{Disable: cfg.Disable, Enable: cfg.Enable, Weights: cfg.Weights}
+	top, err := CurrentBackend().TopLevel(repoPath)
+	if err != nil || top == "" {
+		return hintdetector.Select(specs, sel)
+	}
+	repo := filepath.Base(top)
+	sel.Auto = cfg.Auto
+	sel.Stack = hintdetector.DetectStack(top)
+	sel.Disable = append(append([]string(nil), cfg.Disable...), cfg.RepoDisable[repo]...)
+	sel.Enable = append(append([]string(nil), cfg.Enable...), cfg.RepoEnable[repo]...)
+	return hintdetector.Select(specs, sel)
+}
+
+// globalDetectors returns every detector not disabled globally, for files
+// read without their repository.
+func globalDetectors() []hintdetector.Spec {
+	specs, cfg := currentDetectors()
+	return hintdetector.Select(specs, hintdetector.Selection{Disable: cfg.Disable, Weights: cfg.Weights})
+}
+
+func currentDetectors() ([]hintdetector.Spec, DetectorSettings) {
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	return append(hintdetector.Builtins(), userDetectors...), detectorCfg
+}
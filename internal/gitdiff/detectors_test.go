@@ -0,0 +1,57 @@
+package gitdiff
+
+import (
+	"md2slack/internal/hintdetector"
+	"slices"
+	"testing"
+)
+
+func TestDetectorsForFollowsRepoStack(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Alice <alice@example.com>", "2026-02-05T10:00:00", map[string]string{
+		"go.mod": "module example.com/api\n",
+	})
+	file := DiffFile{Path: "tools/preview.ts", Additions: []string{"const router = useRouter();"}}
+
+	s := extractSignals(file, DetectorsFor(dir), nil, false)
+	if containsType(s.Types, "framework_change") {
+		t.Fatalf("expected no nextjs signal in a Go repo, got %+v", s)
+	}
+
+	SetDetectorSettings(DetectorSettings{Auto: true, RepoEnable: map[string][]string{GetRepoNameAt(dir): {"nextjs"}}, Weights: map[string]float64{"nextjs": 0.9}})
+	defer SetDetectorSettings(DetectorSettings{Auto: true})
+	s = extractSignals(file, DetectorsFor(dir), nil, false)
+	if !containsType(s.Types, "framework_change") || s.Weights["framework_change"] != 0.9 {
+		t.Fatalf("expected the enabled nextjs detector with its weight, got %+v", s)
+	}
+
+	SetDetectorSettings(DetectorSettings{Auto: true, Disable: []string{"state_guard", "logic"}})
+	writeAndCommit(t, dir, "Alice <alice@example.com>", "2026-02-05T11:00:00", map[string]string{
+		"web/package.json": `{"dependencies": {"next": "15.0.0"}}`,
+	})
+	specs := DetectorsFor(dir)
+	var got []string
+	for _, s := range specs {
+		got = append(got, s.Name)
+	}
+	if !slices.Contains(got, "nextjs") || slices.Contains(got, "drizzle") || slices.Contains(got, "logic") || slices.Contains(got, "state_guard") {
+		t.Fatalf("unexpected detectors for a Next.js repo: %v", got)
+	}
+}
+
+func TestExtractSignalsMarksCodeHints(t *testing.T) {
+	quoting, err := hintdetector.NewRuleDetector(hintdetector.Rule{Name: "charge", Pattern: `(stripe\.\w+\.create\(.*\))`, Signal: "payment_change", Hint: "charges via ${1}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := hintdetector.NewRuleDetector(hintdetector.Rule{Name: "billing", Pattern: `stripe\.`, Signal: "payment_change", Hint: "touches billing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := DiffFile{Path: "api/charge.ts", Additions: []string{"await stripe.charges.create(order.total)"}}
+
+	s := extractSignals(file, []hintdetector.Spec{quoting.Spec(), plain.Spec()}, nil, false)
+	if !slices.Equal(s.CodeHints, []string{"charges via stripe.charges.create(order.total)"}) || !slices.Contains(s.Hints, "touches billing") {
+		t.Fatalf("expected only the capture-group hint marked as code, got %+v", s)
+	}
+}
@@ -1,33 +1,14 @@
 package gitdiff
 
 import (
-	"bytes"
 	"fmt"
-	"os"
-	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
 )
 
-func sh(cmd string) (string, error) {
-	return runGit("", cmd)
-}
-
-func runGit(repoPath string, cmd string) (string, error) {
-	c := exec.Command("bash", "-c", cmd)
-	if strings.TrimSpace(repoPath) != "" {
-		c.Dir = repoPath
-	}
-	var out bytes.Buffer
-	c.Stdout = &out
-	c.Stderr = os.Stderr
-	if err := c.Run(); err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(out.String()), nil
-}
-
 type Output struct {
 	RepoName  string           `json:"repo_name"`
 	Date      string           `json:"date"`
@@ -37,11 +18,14 @@ type Output struct {
 	Diffs     []CommitDiff     `json:"raw_diffs,omitempty"`
 	Summaries []CommitSummary  `json:"summaries,omitempty"`
 	Semantic  []CommitSemantic `json:"semantic,omitempty"`
+	Activity  []Activity       `json:"activity,omitempty"`
+	// Secrets are possible credentials found in the commits. They are
+	// masked everywhere above.
+	Secrets []SecretFinding `json:"secrets,omitempty"`
 }
 
 func UpdateGitFetch(repoPath string) error {
-	_, err := runGit(repoPath, `git fetch --all`)
-	return err
+	return CurrentBackend().Fetch(repoPath)
 }
 
 func GetRepoName() string {
@@ -49,11 +33,11 @@ func GetRepoName() string {
 }
 
 func GetRepoNameAt(repoPath string) string {
-	repo, _ := runGit(repoPath, `basename $(git rev-parse --show-toplevel 2>/dev/null) 2>/dev/null`)
-	if repo == "" {
+	top, err := CurrentBackend().TopLevel(repoPath)
+	if err != nil || top == "" {
 		return "unknown"
 	}
-	return repo
+	return filepath.Base(top)
 }
 
 func GenerateFacts(date string, extra string) (*Output, error) {
@@ -67,75 +51,90 @@ func GenerateFactsWithOptions(date string, extra string, repoPath string, author
 	// 1. Get user and repo info
 	fullAuthor := strings.TrimSpace(authorOverride)
 	if fullAuthor == "" {
-		fullAuthor, _ = runGit(repoPath, `git config user.name`)
+		fullAuthor, _ = CurrentBackend().UserName(repoPath)
 	}
 	repo := GetRepoNameAt(repoPath)
 
 	// 2. Format date for Git (it strictly needs YYYY-MM-DD)
-	isoDate := toISODate(date)
+	isoDate := ISODate(date)
 
-	// Broaden author search: split by comma and handle each part
-	authors := strings.Split(authorOverride, ",")
-	var authorFlags []string
-	for _, a := range authors {
-		a = strings.TrimSpace(a)
-		if a == "" {
-			continue
-		}
-		// Basic normalization
-		part := a
-		if idx := strings.Index(a, " "); idx > 0 {
-			part = a[:idx]
-		}
-		if idx := strings.Index(part, "."); idx > 0 {
-			part = part[:idx]
-		}
-		authorFlags = append(authorFlags, fmt.Sprintf("--author=\"%s\"", part))
+	day, err := time.ParseInLocation("2006-01-02", isoDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", date, err)
 	}
-
-	authorStr := strings.Join(authorFlags, " ")
-	if authorStr == "" {
-		// Fallback to current git user
-		current, _ := runGit(repoPath, `git config user.name`)
-		authorStr = fmt.Sprintf("--author=\"%s\"", strings.TrimSpace(current))
+	commits, err := CurrentBackend().Commits(repoPath, CommitQuery{
+		Authors:  authorPatterns(repoPath, authorOverride),
+		Since:    day,
+		Until:    day.Add(24*time.Hour - time.Second),
+		NoMerges: true,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	raw, err := runGit(repoPath, fmt.Sprintf(`
-git log %s --regexp-ignore-case \
---since="%s 00:00:00" \
---until="%s 23:59:59" \
---no-merges \
---format="commit %%h%%n%%s" \
--p -U1 --all
-`, authorStr, isoDate, isoDate))
+	filter, err := NewDiffFilter(repoPath)
 	if err != nil {
 		return nil, err
 	}
+	// Secrets are masked before any analysis, so no hint, summary or prompt
+	// can carry one out of the process.
+	var secrets []SecretFinding
+	for i := range commits {
+		commits[i].Files = filter.Files(commits[i].Files)
+		secrets = append(secrets, redactCommit(&commits[i])...)
+	}
+
+	// Branch membership is a grouping hint; a failure here is not fatal.
+	hashes := make([]string, len(commits))
+	for i, c := range commits {
+		hashes[i] = c.Hash
+	}
+	branches, _ := CurrentBackend().Branches(repoPath, hashes)
+	branchCommits, _ := CurrentBackend().BranchCommits(repoPath, day)
+	featureBranches := make(map[string]string, len(commits))
+	for i := range commits {
+		commits[i].Branches = branches[commits[i].Hash]
+		hash := commits[i].FullHash
+		if hash == "" {
+			hash = commits[i].Hash
+		}
+		featureBranches[commits[i].Hash] = FeatureBranch(hash, branchCommits)
+	}
 
-	// 3. Parse and Analyze
-	commits := ParseGitLog(raw)
+	// 3. Analyze
+	specs := DetectorsFor(repoPath)
 	var diffs []CommitDiff
 	var semantics []CommitSemantic
 
+	// Signal extraction reads each file twice, before and after the commit,
+	// so at most one file per CPU is analysed at a time.
+	sem := make(chan struct{}, runtime.NumCPU())
 	for _, commit := range commits {
-		diffText, err := runGit(repoPath, buildRawDiffCommand(commit.Hash))
+		diffText, err := CurrentBackend().RawDiff(repoPath, commit.Hash, nil)
 		if err == nil {
-			diffs = append(diffs, CommitDiff{CommitHash: commit.Hash, Diff: diffText})
+			patch, _ := RedactSecrets(filter.Patch(diffText))
+			diffs = append(diffs, CommitDiff{CommitHash: commit.Hash, Diff: patch})
 		} else {
 			diffs = append(diffs, CommitDiff{CommitHash: commit.Hash, Diff: ""})
 		}
 
 		// Parallelize signal extraction for files in this commit
+		rev := commit.FullHash
+		if rev == "" {
+			rev = commit.Hash
+		}
 		commitSignals := make([]Signal, len(commit.Files))
 		var wg sync.WaitGroup
 		for i, file := range commit.Files {
 			wg.Add(1)
+			sem <- struct{}{}
 			go func(idx int, f DiffFile) {
-				defer wg.Done()
-				commitSignals[idx] = ExtractSignals(f)
+				defer func() { <-sem; wg.Done() }()
+				commitSignals[idx] = extractSignalsAt(repoPath, rev, f, specs)
 			}(i, file)
 		}
 		wg.Wait()
+		ApplyTestCoverage(commit.Files, commitSignals)
 
 		var signals []Signal
 		touchesTests := false
@@ -154,6 +153,8 @@ git log %s --regexp-ignore-case \
 			Signals:      signals,
 			FilesTouched: len(commit.Files),
 			TouchesTests: touchesTests,
+			Branches:     commit.Branches,
+			Branch:       featureBranches[commit.Hash],
 		})
 	}
 
@@ -166,12 +167,46 @@ git log %s --regexp-ignore-case \
 		Commits:  commits,
 		Diffs:    diffs,
 		Semantic: semantics,
+		Secrets:  secrets,
 	}
 
 	return out, nil
 }
 
-func toISODate(date string) string {
+// authorPatterns turns a comma separated author override into git author
+// patterns, falling back to the repo's git user.
+func authorPatterns(repoPath string, authorOverride string) []string {
+	// Broaden author search: split by comma and handle each part
+	authors := strings.Split(authorOverride, ",")
+	var patterns []string
+	for _, a := range authors {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		// Basic normalization
+		part := a
+		if idx := strings.Index(a, " "); idx > 0 {
+			part = a[:idx]
+		}
+		if idx := strings.Index(part, "."); idx > 0 {
+			part = part[:idx]
+		}
+		patterns = append(patterns, part)
+	}
+
+	if len(patterns) == 0 {
+		// Fallback to current git user
+		current, _ := CurrentBackend().UserName(repoPath)
+		patterns = []string{strings.TrimSpace(current)}
+	}
+	return patterns
+}
+
+// ISODate converts the MM-DD-YYYY dates accepted on the command line to the
+// YYYY-MM-DD form git and the web UI use. Other inputs are returned as-is.
+func ISODate(date string) string {
+	date = strings.TrimSpace(date)
 	t, err := time.Parse("01-02-2006", date)
 	if err == nil {
 		return t.Format("2006-01-02")
@@ -179,37 +214,18 @@ func toISODate(date string) string {
 	return date // Return it as-is if parsing fails
 }
 
-func buildRawDiffCommand(hash string) string {
-	excludes := []string{
-		"node_modules", "dist", "build", "vendor", ".next", ".turbo",
-		".cache", "coverage", "tmp", "tmp/*", ".git", ".idea", ".vscode",
-	}
-	var sb strings.Builder
-	sb.WriteString("git show ")
-	sb.WriteString(hash)
-	sb.WriteString(" --format= --unified=0 --minimal --ignore-all-space --no-color -- .")
-	for _, p := range excludes {
-		sb.WriteString(" ':(exclude)")
-		sb.WriteString(p)
-		sb.WriteString("'")
-	}
-	return sb.String()
+func GetRecentCommitDays(repoPath string, days int) ([]string, error) {
+	return CurrentBackend().CommitDays(repoPath, time.Now().AddDate(0, 0, -days))
 }
 
-func GetRecentCommitDays(repoPath string, days int) ([]string, error) {
-	raw, err := runGit(repoPath, fmt.Sprintf(`git log --all --format="%%ad" --date=short --since="%d days ago" | sort -u -r`, days))
-	if err != nil {
-		return nil, err
-	}
-	lines := strings.Split(raw, "\n")
-	var dates []string
-	for _, l := range lines {
-		l = strings.TrimSpace(l)
-		if l != "" {
-			dates = append(dates, l)
-		}
-	}
-	return dates, nil
+// GetUserName returns the configured git user.name for the repo.
+func GetUserName(repoPath string) (string, error) {
+	return CurrentBackend().UserName(repoPath)
+}
+
+// GetRecentAuthors returns author names of the most recent commits on HEAD.
+func GetRecentAuthors(repoPath string, limit int) ([]string, error) {
+	return CurrentBackend().Authors(repoPath, limit)
 }
 
 type GitGraphCommit struct {
@@ -225,13 +241,10 @@ func GetGitGraphData(repoPath string, limit int) ([]GitGraphCommit, error) {
 	if limit <= 0 {
 		limit = 100
 	}
-	// %H: hash, %P: parents, %D: refs, %s: subject, %an: author, %at: date
-	cmd := fmt.Sprintf(`git log --all --format="%%H|%%P|%%D|%%s|%%an|%%at" -n %d`, limit)
-	raw, err := runGit(repoPath, cmd)
-	if err != nil {
-		return nil, err
-	}
+	return CurrentBackend().Graph(repoPath, limit)
+}
 
+func parseGraphLog(raw string) []GitGraphCommit {
 	lines := strings.Split(raw, "\n")
 	var commits []GitGraphCommit
 	for _, l := range lines {
@@ -247,22 +260,7 @@ func GetGitGraphData(repoPath string, limit int) ([]GitGraphCommit, error) {
 		hash := parts[0]
 		parents := strings.Fields(parts[1])
 
-		// Parse refs: e.g. "HEAD -> master, origin/master, tag: v1.0"
-		rawRefs := parts[2]
-		var refs []string
-		if rawRefs != "" {
-			refParts := strings.Split(rawRefs, ",")
-			for _, rp := range refParts {
-				rp = strings.TrimSpace(rp)
-				if strings.HasPrefix(rp, "HEAD -> ") {
-					rp = strings.TrimPrefix(rp, "HEAD -> ")
-				}
-				if strings.HasPrefix(rp, "tag: ") {
-					rp = strings.TrimPrefix(rp, "tag: ")
-				}
-				refs = append(refs, rp)
-			}
-		}
+		refs := parseRefs(parts[2])
 
 		subject := parts[3]
 		author := parts[4]
@@ -279,5 +277,5 @@ func GetGitGraphData(repoPath string, limit int) ([]GitGraphCommit, error) {
 			Date:    date,
 		})
 	}
-	return commits, nil
+	return commits
 }
@@ -0,0 +1,246 @@
+package gitdiff
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// IgnoreFileName is the per-repository file, at the top of the work tree,
+// listing paths to leave out of diffs. It uses .gitignore syntax.
+const IgnoreFileName = ".md2slackignore"
+
+// DefaultExcludes are always left out of diffs: dependencies, build output,
+// editor settings, lockfiles and minified or generated assets. A repo can
+// bring one back with a "!" pattern in its ignore file.
+var DefaultExcludes = []string{
+	"node_modules/", "dist/", "build/", "vendor/", ".next/", ".turbo/",
+	".cache/", "coverage/", "tmp/", ".idea/", ".vscode/",
+	"package-lock.json", "yarn.lock", "pnpm-lock.yaml", "go.sum", "Cargo.lock",
+	"poetry.lock", "Pipfile.lock", "composer.lock", "Gemfile.lock",
+	"*.min.js", "*.min.css", "*.map",
+}
+
+// DiffLimits are the global diff settings. Excludes are added after
+// DefaultExcludes and before the repo's ignore file. A zero line cap means
+// no limit.
+type DiffLimits struct {
+	Excludes       []string
+	MaxFileLines   int // changed lines kept per file
+	MaxCommitLines int // changed lines kept per commit, across its files
+}
+
+var (
+	limitsMu sync.RWMutex
+	limits   DiffLimits
+)
+
+// SetDiffLimits replaces the global diff settings used by NewDiffFilter.
+func SetDiffLimits(l DiffLimits) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	limits = l
+}
+
+func currentDiffLimits() DiffLimits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return limits
+}
+
+// DiffFilter drops excluded files from a diff and caps the number of changed
+// lines, so one generated file cannot fill the LLM context. The same filter
+// is applied to parsed files and raw patches.
+type DiffFilter struct {
+	matcher        gitignore.Matcher
+	MaxFileLines   int
+	MaxCommitLines int
+}
+
+// NewDiffFilter builds the filter for a repository from DefaultExcludes, the
+// global DiffLimits and the repo's ignore file, if there is one.
+func NewDiffFilter(repoPath string) (*DiffFilter, error) {
+	l := currentDiffLimits()
+	patterns := append(append([]string(nil), DefaultExcludes...), l.Excludes...)
+	if top, err := CurrentBackend().TopLevel(repoPath); err == nil && top != "" {
+		repoPatterns, err := readIgnoreFile(filepath.Join(top, IgnoreFileName))
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, repoPatterns...)
+	}
+	return newDiffFilter(patterns, l.MaxFileLines, l.MaxCommitLines), nil
+}
+
+func newDiffFilter(patterns []string, maxFileLines int, maxCommitLines int) *DiffFilter {
+	var ps []gitignore.Pattern
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p != "" && !strings.HasPrefix(p, "#") {
+			ps = append(ps, gitignore.ParsePattern(p, nil))
+		}
+	}
+	return &DiffFilter{
+		matcher:        gitignore.NewMatcher(ps),
+		MaxFileLines:   maxFileLines,
+		MaxCommitLines: maxCommitLines,
+	}
+}
+
+func readIgnoreFile(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var patterns []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		patterns = append(patterns, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", IgnoreFileName, err)
+	}
+	return patterns, nil
+}
+
+// Excluded reports whether a repo-relative path is left out. As with
+// .gitignore, a path inside an excluded directory is excluded too.
+func (f *DiffFilter) Excluded(path string) bool {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for i := 1; i < len(parts); i++ {
+		if f.matcher.Match(parts[:i], true) {
+			return true
+		}
+	}
+	return f.matcher.Match(parts, false)
+}
+
+// Files drops excluded files and trims the changed lines of the rest to the
+// caps, additions first, in their hunks too. Files past the commit cap keep
+// their path and flags but no lines. OmittedLines records how many lines
+// were cut.
+func (f *DiffFilter) Files(files []DiffFile) []DiffFile {
+	var out []DiffFile
+	budget := f.MaxCommitLines
+	for _, file := range files {
+		if f.Excluded(file.Path) {
+			continue
+		}
+		limit := f.lineLimit(budget)
+		total := len(file.Additions) + len(file.Deletions)
+		if limit >= 0 && total > limit {
+			if len(file.Additions) > limit {
+				file.Additions = file.Additions[:limit]
+			}
+			file.Deletions = file.Deletions[:min(len(file.Deletions), limit-len(file.Additions))]
+			file.Hunks = trimHunks(file.Hunks, len(file.Additions), len(file.Deletions))
+			file.OmittedLines = total - limit
+		}
+		budget = spend(budget, len(file.Additions)+len(file.Deletions))
+		out = append(out, file)
+	}
+	return out
+}
+
+// lineLimit returns how many changed lines the next file may keep given the
+// remaining commit budget (-1 once spent), or -1 for no limit. A budget of
+// 0 means the commit is not capped.
+func (f *DiffFilter) lineLimit(budget int) int {
+	limit := f.MaxFileLines
+	if limit <= 0 {
+		limit = -1
+	}
+	switch {
+	case budget < 0:
+		return 0
+	case budget > 0 && (limit < 0 || budget < limit):
+		return budget
+	}
+	return limit
+}
+
+// spend takes lines from the remaining commit budget. A budget of 0 is
+// uncapped and stays so; a spent budget becomes -1.
+func spend(budget int, lines int) int {
+	if budget <= 0 {
+		return budget
+	}
+	if budget -= lines; budget <= 0 {
+		return -1
+	}
+	return budget
+}
+
+// Patch applies the filter to a unified diff: excluded file sections are
+// removed and changed lines past the caps are replaced by a note saying how
+// many were left out.
+func (f *DiffFilter) Patch(patch string) string {
+	if patch == "" {
+		return patch
+	}
+	var out []string
+	budget := f.MaxCommitLines
+	for _, section := range splitPatch(patch) {
+		if path := patchPath(section[0]); path != "" && f.Excluded(path) {
+			continue
+		}
+		limit := f.lineLimit(budget)
+		kept, omitted := 0, 0
+		inHunk := false
+		for _, line := range section {
+			if strings.HasPrefix(line, "@@") {
+				inHunk = true
+			}
+			if inHunk && isChangedLine(line) {
+				if limit >= 0 && kept >= limit {
+					omitted++
+					continue
+				}
+				kept++
+			}
+			out = append(out, line)
+		}
+		if omitted > 0 {
+			out = append(out, fmt.Sprintf("... %d changed lines omitted", omitted))
+		}
+		budget = spend(budget, kept)
+	}
+	return strings.Join(out, "\n")
+}
+
+// splitPatch splits a patch into per-file sections, each starting with its
+// "diff --git" line. Text before the first file is kept as its own section.
+func splitPatch(patch string) [][]string {
+	var sections [][]string
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "diff --git ") || len(sections) == 0 {
+			sections = append(sections, nil)
+		}
+		sections[len(sections)-1] = append(sections[len(sections)-1], line)
+	}
+	return sections
+}
+
+func patchPath(header string) string {
+	if m := diffHeaderRe.FindStringSubmatch(header); m != nil {
+		return m[2]
+	}
+	return ""
+}
+
+// isChangedLine reports whether a line of a hunk is added or removed. The
+// "---" and "+++" file headers come before the first hunk, so inside one a
+// removed "-- comment" is a changed line like any other.
+func isChangedLine(line string) bool {
+	return strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")
+}
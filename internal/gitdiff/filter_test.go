@@ -0,0 +1,161 @@
+package gitdiff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffFilterExcluded(t *testing.T) {
+	f := newDiffFilter(append(append([]string(nil), DefaultExcludes...), "*.pb.go", "# comment", "docs/generated/", "!vendor/keep.go"), 0, 0)
+	cases := map[string]bool{
+		"main.go":                     false,
+		"web/node_modules/react/x.js": true,
+		"package-lock.json":           true,
+		"web/yarn.lock":               true,
+		"api/user.pb.go":              true,
+		"docs/generated/api.md":       true,
+		"docs/guide.md":               false,
+		"static/app.min.js":           true,
+		"build.go":                    false,
+		"vendor/keep.go":              true, // excluded directories cannot be re-included, as in git
+		"internal/builder/builder.go": false,
+	}
+	for path, want := range cases {
+		if got := f.Excluded(path); got != want {
+			t.Errorf("Excluded(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	lockfile := newDiffFilter(append(append([]string(nil), DefaultExcludes...), "!go.sum"), 0, 0)
+	if lockfile.Excluded("go.sum") {
+		t.Fatalf("expected go.sum to be re-included")
+	}
+}
+
+func TestDiffFilterFilesCaps(t *testing.T) {
+	lines := func(n int, prefix string) []string {
+		var out []string
+		for i := 0; i < n; i++ {
+			out = append(out, prefix)
+		}
+		return out
+	}
+	f := newDiffFilter(DefaultExcludes, 10, 15)
+	got := f.Files([]DiffFile{
+		{Path: "a.go", Additions: lines(8, "a"), Deletions: lines(4, "d")},
+		{Path: "yarn.lock", Additions: lines(100, "x")},
+		{Path: "b.go", Additions: lines(3, "b"), Deletions: lines(3, "d")},
+		{Path: "c.go", IsNew: true, Additions: lines(2, "c")},
+	})
+	if len(got) != 3 {
+		t.Fatalf("expected the lockfile to be dropped, got %d files", len(got))
+	}
+	if len(got[0].Additions) != 8 || len(got[0].Deletions) != 2 || got[0].OmittedLines != 2 {
+		t.Fatalf("unexpected per-file cap: %d+%d omitted %d", len(got[0].Additions), len(got[0].Deletions), got[0].OmittedLines)
+	}
+	if len(got[1].Additions) != 3 || len(got[1].Deletions) != 2 || got[1].OmittedLines != 1 {
+		t.Fatalf("unexpected commit cap: %d+%d omitted %d", len(got[1].Additions), len(got[1].Deletions), got[1].OmittedLines)
+	}
+	if got[2].Path != "c.go" || !got[2].IsNew || len(got[2].Additions) != 0 || got[2].OmittedLines != 2 {
+		t.Fatalf("expected the last file to keep only its path: %+v", got[2])
+	}
+}
+
+func TestDiffFilterPatch(t *testing.T) {
+	patch := strings.Join([]string{
+		"diff --git a/main.go b/main.go",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1 +1,4 @@",
+		"-old",
+		"+one",
+		"+two",
+		"+three",
+		"diff --git a/dist/app.js b/dist/app.js",
+		"+++ b/dist/app.js",
+		"+bundle",
+	}, "\n")
+	got := newDiffFilter(DefaultExcludes, 2, 0).Patch(patch)
+	want := strings.Join([]string{
+		"diff --git a/main.go b/main.go",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1 +1,4 @@",
+		"-old",
+		"+one",
+		"... 2 changed lines omitted",
+	}, "\n")
+	if got != want {
+		t.Fatalf("unexpected patch:\n%s", got)
+	}
+
+	// Removed SQL comments look like "---" but count against the cap.
+	sql := strings.Join([]string{
+		"diff --git a/schema.sql b/schema.sql",
+		"--- a/schema.sql",
+		"+++ b/schema.sql",
+		"@@ -1,3 +1 @@",
+		"--- users",
+		"---- legacy",
+		"+++ counter",
+		"-drop table x;",
+	}, "\n")
+	got = newDiffFilter(nil, 1, 0).Patch(sql)
+	want = strings.Join([]string{
+		"diff --git a/schema.sql b/schema.sql",
+		"--- a/schema.sql",
+		"+++ b/schema.sql",
+		"@@ -1,3 +1 @@",
+		"--- users",
+		"... 3 changed lines omitted",
+	}, "\n")
+	if got != want {
+		t.Fatalf("unexpected sql patch:\n%s", got)
+	}
+}
+
+func TestGenerateFactsAppliesIgnoreFile(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-05T10:00:00", map[string]string{
+		"main.go":           "package main\n",
+		"package-lock.json": "{}\n",
+		"gen/api.go":        "package gen\n",
+	})
+	if err := os.WriteFile(filepath.Join(dir, IgnoreFileName), []byte("# generated code\ngen/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := GenerateFactsWithOptions("2026-02-05", "", dir, "Ana")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Commits) != 1 {
+		t.Fatalf("expected one commit, got %d", len(out.Commits))
+	}
+	if files := out.Commits[0].Files; len(files) != 1 || files[0].Path != "main.go" {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+	if d := out.Diffs[0].Diff; !strings.Contains(d, "main.go") || strings.Contains(d, "package-lock") || strings.Contains(d, "gen/api.go") {
+		t.Fatalf("unexpected raw diff:\n%s", d)
+	}
+}
+
+func TestDiffFilterFilesTrimsHunks(t *testing.T) {
+	patch := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n" +
+		"@@ -1,2 +1,3 @@\n-x\n+a\n+b\n y\n" +
+		"@@ -10 +11,2 @@\n z\n+c\n"
+	f := newDiffFilter(nil, 2, 0)
+	got := f.Files(parseFiles(strings.Split(patch, "\n")))
+	if len(got) != 1 || len(got[0].Hunks) != 1 {
+		t.Fatalf("expected the second hunk to be dropped: %+v", got)
+	}
+	var ops []byte
+	for _, l := range got[0].Hunks[0].Lines {
+		ops = append(ops, l.Op)
+	}
+	if string(ops) != "++ " || got[0].OmittedLines != 2 {
+		t.Fatalf("unexpected trimmed hunk %q, omitted %d", ops, got[0].OmittedLines)
+	}
+}
@@ -0,0 +1,193 @@
+package gitdiff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GitError is returned when a git command fails. Stderr holds git's own
+// message, e.g. "fatal: not a git repository".
+type GitError struct {
+	Args     []string
+	Dir      string
+	ExitCode int
+	Stderr   string
+	Err      error
+}
+
+func (e *GitError) Error() string {
+	cmd := "git"
+	if len(e.Args) > 0 {
+		cmd += " " + e.Args[0]
+	}
+	if e.Stderr != "" {
+		return fmt.Sprintf("%s: %s", cmd, e.Stderr)
+	}
+	return fmt.Sprintf("%s: %v", cmd, e.Err)
+}
+
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// Git runs git with args in repoPath (the current directory when empty) and
+// returns its trimmed stdout. Arguments are passed straight to git, never
+// through a shell.
+func Git(repoPath string, args ...string) (string, error) {
+	c := exec.Command("git", args...)
+	if strings.TrimSpace(repoPath) != "" {
+		c.Dir = repoPath
+	}
+	var out, errOut bytes.Buffer
+	c.Stdout = &out
+	c.Stderr = &errOut
+	if err := c.Run(); err != nil {
+		gitErr := &GitError{
+			Args:     args,
+			Dir:      repoPath,
+			ExitCode: -1,
+			Stderr:   strings.TrimSpace(errOut.String()),
+			Err:      err,
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			gitErr.ExitCode = exitErr.ExitCode()
+		}
+		return "", gitErr
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// LogOptions describes a git log invocation.
+type LogOptions struct {
+	Authors    []string // matched as fixed strings, any of them
+	Committers []string // matched like Authors
+	Since      string
+	Until      string
+	All        bool
+	NoMerges   bool
+	Merges     bool // only merge commits
+	IgnoreCase bool
+	Format     string // passed as --format
+	Date       string // passed as --date, e.g. "short"
+	Patch      bool
+	Unified    int  // context lines with Patch; 0 means git's default
+	Renames    bool // detect renames and copies (-M -C) with Patch
+	MaxCount   int
+}
+
+// Args returns the git arguments for the options.
+func (o LogOptions) Args() []string {
+	args := []string{"log"}
+	for _, a := range o.Authors {
+		args = append(args, "--author="+a)
+	}
+	for _, c := range o.Committers {
+		args = append(args, "--committer="+c)
+	}
+	if len(o.Authors) > 0 || len(o.Committers) > 0 {
+		// Author names come from config and the web UI; never treat them as regexes
+		args = append(args, "--fixed-strings")
+	}
+	if o.IgnoreCase {
+		args = append(args, "--regexp-ignore-case")
+	}
+	if o.Since != "" {
+		args = append(args, "--since="+o.Since)
+	}
+	if o.Until != "" {
+		args = append(args, "--until="+o.Until)
+	}
+	if o.NoMerges {
+		args = append(args, "--no-merges")
+	}
+	if o.Merges {
+		args = append(args, "--merges")
+	}
+	if o.Format != "" {
+		args = append(args, "--format="+o.Format)
+	}
+	if o.Date != "" {
+		args = append(args, "--date="+o.Date)
+	}
+	if o.Patch {
+		args = append(args, "-p")
+		if o.Unified > 0 {
+			args = append(args, "-U"+strconv.Itoa(o.Unified))
+		}
+		if o.Renames {
+			args = append(args, "-M", "-C")
+		}
+	}
+	if o.MaxCount > 0 {
+		args = append(args, "-n", strconv.Itoa(o.MaxCount))
+	}
+	if o.All {
+		args = append(args, "--all")
+	}
+	return args
+}
+
+// Log runs git log.
+func Log(repoPath string, opts LogOptions) (string, error) {
+	return Git(repoPath, opts.Args()...)
+}
+
+// ShowOptions describes a git show invocation for a single revision.
+type ShowOptions struct {
+	Rev            string
+	Format         string // passed as --format; "" suppresses the header
+	Unified        int
+	Minimal        bool
+	IgnoreAllSpace bool
+	Paths          []string
+	Excludes       []string // pathspecs excluded with :(exclude)
+}
+
+// Args returns the git arguments for the options.
+func (o ShowOptions) Args() []string {
+	args := []string{"show", o.Rev, "--format=" + o.Format, "--unified=" + strconv.Itoa(o.Unified), "--no-color"}
+	if o.Minimal {
+		args = append(args, "--minimal")
+	}
+	if o.IgnoreAllSpace {
+		args = append(args, "--ignore-all-space")
+	}
+	args = append(args, "--")
+	paths := o.Paths
+	if len(paths) == 0 && len(o.Excludes) > 0 {
+		paths = []string{"."}
+	}
+	args = append(args, paths...)
+	for _, p := range o.Excludes {
+		args = append(args, ":(exclude)"+p)
+	}
+	return args
+}
+
+// Show runs git show.
+func Show(repoPath string, opts ShowOptions) (string, error) {
+	if strings.HasPrefix(opts.Rev, "-") {
+		return "", fmt.Errorf("invalid revision %q", opts.Rev)
+	}
+	return Git(repoPath, opts.Args()...)
+}
+
+// ConfigGet returns a git config value, or "" when the key is unset.
+func ConfigGet(repoPath string, key string) (string, error) {
+	out, err := Git(repoPath, "config", "--get", key)
+	var gitErr *GitError
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		return "", nil
+	}
+	return out, err
+}
+
+// TopLevel returns the root directory of the work tree containing repoPath.
+func TopLevel(repoPath string) (string, error) {
+	return Git(repoPath, "rev-parse", "--show-toplevel")
+}
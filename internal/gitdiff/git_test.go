@@ -0,0 +1,87 @@
+package gitdiff
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if _, err := Git(dir, "init", "-q"); err != nil {
+		t.Skipf("git not available: %v", err)
+	}
+	return dir
+}
+
+func commitAs(t *testing.T, dir string, author string, date string, file string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(file+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Git(dir, "add", file); err != nil {
+		t.Fatal(err)
+	}
+	// git log --since/--until filter on the committer date
+	t.Setenv("GIT_COMMITTER_DATE", date+"T10:00:00")
+	_, err := Git(dir, "-c", "user.name="+author, "-c", "user.email=dev@example.com",
+		"commit", "-q", "-m", "add "+file, "--date="+date+"T10:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLogAuthorIsNotInterpretedByShell(t *testing.T) {
+	dir := initTestRepo(t)
+	author := `Ana "$(touch pwned)" O'Brien`
+	commitAs(t, dir, author, "2026-02-05", "a.txt")
+
+	out, err := Log(dir, LogOptions{Authors: []string{author}, Format: "%an", All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != author {
+		t.Fatalf("expected commit by %q, got %q", author, out)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Fatal("author name was executed by a shell")
+	}
+}
+
+func TestGitErrorIncludesStderr(t *testing.T) {
+	_, err := Git(t.TempDir(), "rev-parse", "--show-toplevel")
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("expected GitError, got %v", err)
+	}
+	if gitErr.ExitCode == 0 || !strings.Contains(gitErr.Stderr, "not a git repository") {
+		t.Fatalf("unexpected error: %+v", gitErr)
+	}
+}
+
+func TestConfigGetUnsetKey(t *testing.T) {
+	dir := initTestRepo(t)
+	val, err := ConfigGet(dir, "md2slack.missing")
+	if err != nil || val != "" {
+		t.Fatalf("expected empty value for unset key, got %q %v", val, err)
+	}
+}
+
+func TestGenerateFactsWithQuotedAuthor(t *testing.T) {
+	dir := initTestRepo(t)
+	commitAs(t, dir, `Zoe "Z" $(whoami)`, "2026-02-05", "b.go")
+
+	out, err := GenerateFactsWithOptions("02-05-2026", "", dir, `Zoe`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Commits) != 1 || out.RepoName != filepath.Base(dir) {
+		t.Fatalf("unexpected facts: %+v", out)
+	}
+	if len(out.Diffs) != 1 || !strings.Contains(out.Diffs[0].Diff, "b.go") {
+		t.Fatalf("expected raw diff for commit, got %+v", out.Diffs)
+	}
+}
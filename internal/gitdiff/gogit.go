@@ -0,0 +1,759 @@
+package gitdiff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	gitdiffutil "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// GoGitBackend reads repositories with go-git, for environments without the
+// git binary. Raw diffs do not support --ignore-all-space, so whitespace-only
+// changes show up in them.
+type GoGitBackend struct{}
+
+func (GoGitBackend) Name() string { return "go-git" }
+
+func openRepo(repoPath string) (*git.Repository, error) {
+	if strings.TrimSpace(repoPath) == "" {
+		repoPath = "."
+	}
+	return git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true})
+}
+
+func (GoGitBackend) TopLevel(repoPath string) (string, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return "", err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return wt.Filesystem.Root(), nil
+}
+
+func (GoGitBackend) UserName(repoPath string) (string, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := repo.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return "", err
+	}
+	return cfg.User.Name, nil
+}
+
+func (GoGitBackend) Fetch(repoPath string) error {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return err
+	}
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return err
+	}
+	for _, remote := range remotes {
+		err := remote.Fetch(&git.FetchOptions{})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return fmt.Errorf("fetch %s: %w", remote.Config().Name, err)
+		}
+	}
+	return nil
+}
+
+// allCommits iterates commits reachable from any ref, newest committer date first.
+func allCommits(repo *git.Repository, since, until *time.Time) (object.CommitIter, error) {
+	return repo.Log(&git.LogOptions{
+		All:   true,
+		Order: git.LogOrderCommitterTime,
+		Since: since,
+		Until: until,
+	})
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (GoGitBackend) Commits(repoPath string, q CommitQuery) ([]Commit, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := allCommits(repo, timePtr(q.Since), timePtr(q.Until))
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	refs, err := refNamesByCommit(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if q.NoMerges && c.NumParents() > 1 {
+			return nil
+		}
+		if q.MergesOnly && c.NumParents() < 2 {
+			return nil
+		}
+		if !matchesAuthor(c.Author, q.Authors) || !matchesAuthor(c.Committer, q.Committers) {
+			return nil
+		}
+		var files []DiffFile
+		if !q.NoPatch {
+			patch, err := commitPatch(c)
+			if err != nil {
+				return err
+			}
+			files, err = diffFilesFromPatch(repo, patch)
+			if err != nil {
+				return err
+			}
+		}
+		commits = append(commits, commitFromObject(c, refs[c.Hash], files))
+		return nil
+	})
+	return commits, err
+}
+
+func commitFromObject(c *object.Commit, refs []string, files []DiffFile) Commit {
+	subject, body, _ := strings.Cut(c.Message, "\n")
+	parents := []string{}
+	for _, p := range c.ParentHashes {
+		parents = append(parents, p.String())
+	}
+	commit := Commit{
+		Hash:        shortHash(c.Hash.String()),
+		FullHash:    c.Hash.String(),
+		Message:     strings.TrimSpace(subject),
+		Body:        strings.TrimSpace(body),
+		Files:       files,
+		Author:      Person{Name: c.Author.Name, Email: c.Author.Email},
+		Committer:   Person{Name: c.Committer.Name, Email: c.Committer.Email},
+		AuthoredAt:  time.Unix(c.Author.When.Unix(), 0),
+		CommittedAt: time.Unix(c.Committer.When.Unix(), 0),
+		Parents:     parents,
+		Refs:        refs,
+	}
+	commit.Trailers, commit.CoAuthors = ParseTrailers(commit.Body)
+	return commit
+}
+
+// matchesAuthor mirrors git log --author (or --committer) --fixed-strings
+// --regexp-ignore-case.
+func matchesAuthor(sig object.Signature, authors []string) bool {
+	if len(authors) == 0 {
+		return true
+	}
+	ident := strings.ToLower(fmt.Sprintf("%s <%s>", sig.Name, sig.Email))
+	for _, a := range authors {
+		if strings.Contains(ident, strings.ToLower(a)) {
+			return true
+		}
+	}
+	return false
+}
+
+// commitPatch diffs a commit against its first parent, or the empty tree for
+// a root commit.
+func commitPatch(c *object.Commit) (*object.Patch, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	if parentTree == nil {
+		parentTree = &object.Tree{}
+	}
+	return parentTree.Patch(tree)
+}
+
+// diffFilesFromPatch converts a go-git patch into DiffFiles with the same
+// one-line-context hunks the exec backend asks git for. go-git detects
+// renames like git -M but has no copy detection, so a copy shows up as a
+// new file here.
+func diffFilesFromPatch(repo *git.Repository, patch *object.Patch) ([]DiffFile, error) {
+	var files []DiffFile
+	for _, fp := range patch.FilePatches() {
+		from, to := fp.Files()
+		f := DiffFile{IsNew: from == nil, IsDeleted: to == nil, IsBinary: fp.IsBinary()}
+		if to != nil {
+			f.Path = to.Path()
+		} else if from != nil {
+			f.Path = from.Path()
+		}
+		f.IsTest = isTestFile(f.Path)
+		if from != nil && to != nil && from.Path() != to.Path() {
+			f.OldPath, f.IsRename = from.Path(), true
+			f.Similarity = 100
+			if from.Hash() != to.Hash() {
+				src, err := blobContent(repo, from.Hash())
+				if err != nil {
+					return nil, err
+				}
+				dst, err := blobContent(repo, to.Hash())
+				if err != nil {
+					return nil, err
+				}
+				f.Similarity = similarity(src, dst, fp.IsBinary())
+			}
+		}
+		if !fp.IsBinary() {
+			applyChunks(&f, fp.Chunks(), 1)
+		}
+		files = append(files, f)
+	}
+	// go-git lists renames after the other changes; git orders by path
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func blobContent(repo *git.Repository, h plumbing.Hash) (string, error) {
+	blob, err := repo.BlobObject(h)
+	if err != nil {
+		return "", err
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	return string(data), err
+}
+
+// similarity computes git's similarity index of a rename: how much of the
+// larger version is made of spans also found in the other, as a percentage
+// rounded down. Like git, spans end at a newline or after 64 bytes and
+// text files ignore the CR of CRLF.
+func similarity(src string, dst string, binary bool) int {
+	if len(dst) == 0 {
+		return 0
+	}
+	spans := func(s string) map[string]int {
+		out := make(map[string]int)
+		var span []byte
+		for i := 0; i < len(s); i++ {
+			c := s[i]
+			if !binary && c == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+				continue
+			}
+			span = append(span, c)
+			if len(span) < 64 && c != '\n' {
+				continue
+			}
+			out[string(span)] += len(span)
+			span = span[:0]
+		}
+		if len(span) > 0 {
+			out[string(span)] += len(span)
+		}
+		return out
+	}
+	a, b := spans(src), spans(dst)
+	copied := 0
+	for span, n := range a {
+		copied += min(n, b[span])
+	}
+	// git scores out of 60000 before converting to a percentage
+	const maxScore = 60000
+	score := copied * maxScore / max(len(src), len(dst))
+	return score * 100 / maxScore
+}
+
+// isBinaryText uses git's heuristic: a NUL byte in the first 8000 bytes.
+func isBinaryText(s string) bool {
+	return strings.IndexByte(s[:min(len(s), 8000)], 0) >= 0
+}
+
+// textChunk adapts a line diff of two strings to go-git's diff.Chunk.
+type textChunk struct {
+	content string
+	op      diff.Operation
+}
+
+func (c textChunk) Content() string      { return c.content }
+func (c textChunk) Type() diff.Operation { return c.op }
+
+// textChunks diffs two versions of a file line by line.
+func textChunks(before, after string) []diff.Chunk {
+	var chunks []diff.Chunk
+	for _, d := range gitdiffutil.Do(before, after) {
+		switch d.Type {
+		case diffmatchpatch.DiffEqual:
+			chunks = append(chunks, textChunk{d.Text, diff.Equal})
+		case diffmatchpatch.DiffInsert:
+			chunks = append(chunks, textChunk{d.Text, diff.Add})
+		case diffmatchpatch.DiffDelete:
+			chunks = append(chunks, textChunk{d.Text, diff.Delete})
+		}
+	}
+	return chunks
+}
+
+func chunkLines(content string) []string {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+func (GoGitBackend) RawDiff(repoPath string, hash string, excludes []string) (string, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return "", err
+	}
+	h, err := repo.ResolveRevision(plumbing.Revision(hash))
+	if err != nil {
+		return "", err
+	}
+	c, err := repo.CommitObject(*h)
+	if err != nil {
+		return "", err
+	}
+	patch, err := commitPatch(c)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := diff.NewUnifiedEncoder(&buf, 0).Encode(filteredPatch{patch, excludes}); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// filteredPatch drops file patches whose paths match an exclude pathspec.
+type filteredPatch struct {
+	*object.Patch
+	excludes []string
+}
+
+func (p filteredPatch) FilePatches() []diff.FilePatch {
+	var out []diff.FilePatch
+	for _, fp := range p.Patch.FilePatches() {
+		from, to := fp.Files()
+		if (to != nil && isExcluded(to.Path(), p.excludes)) || (to == nil && from != nil && isExcluded(from.Path(), p.excludes)) {
+			continue
+		}
+		out = append(out, fp)
+	}
+	return out
+}
+
+// isExcluded matches git's :(exclude) pathspecs as passed to RawDiff: a
+// plain name excludes that directory or file at the top level, and a glob
+// is matched against the path.
+func isExcluded(p string, excludes []string) bool {
+	for _, ex := range excludes {
+		if p == ex || strings.HasPrefix(p, ex+"/") {
+			return true
+		}
+		if ok, _ := path.Match(ex, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (GoGitBackend) CommitDays(repoPath string, since time.Time) ([]string, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := allCommits(repo, timePtr(since), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var days []string
+	err = iter.ForEach(func(c *object.Commit) error {
+		days = append(days, c.Author.When.Format("2006-01-02"))
+		return nil
+	})
+	return uniqueDaysDesc(days), err
+}
+
+func (GoGitBackend) Graph(repoPath string, limit int) ([]GitGraphCommit, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := refNamesByCommit(repo)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := allCommits(repo, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var commits []GitGraphCommit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if limit > 0 && len(commits) >= limit {
+			return storer.ErrStop
+		}
+		parents := []string{}
+		for _, p := range c.ParentHashes {
+			parents = append(parents, p.String())
+		}
+		commits = append(commits, GitGraphCommit{
+			Hash:    c.Hash.String(),
+			Parents: parents,
+			Refs:    refs[c.Hash],
+			Subject: strings.TrimSpace(strings.SplitN(c.Message, "\n", 2)[0]),
+			Author:  c.Author.Name,
+			Date:    c.Author.When.Unix(),
+		})
+		return nil
+	})
+	return commits, err
+}
+
+// refNamesByCommit maps commits to the short ref names git log %D shows,
+// after the "HEAD -> " and "tag: " prefixes are stripped.
+func refNamesByCommit(repo *git.Repository) (map[plumbing.Hash][]string, error) {
+	out := make(map[plumbing.Hash][]string)
+	head, headErr := repo.Head()
+	iter, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		if !name.IsBranch() && !name.IsRemote() && !name.IsTag() {
+			return nil
+		}
+		resolved, err := repo.Reference(name, true)
+		if err != nil {
+			return nil
+		}
+		hash := resolved.Hash()
+		if name.IsTag() {
+			// Annotated tags point at a tag object, not the commit
+			if tag, err := repo.TagObject(hash); err == nil {
+				hash = tag.Target
+			}
+		}
+		out[hash] = append(out[hash], name.Short())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if headErr == nil && !head.Name().IsBranch() {
+		out[head.Hash()] = append([]string{"HEAD"}, out[head.Hash()]...)
+	}
+	for h := range out {
+		sort.Strings(out[h])
+	}
+	return out, nil
+}
+
+func (GoGitBackend) Authors(repoPath string, limit int) ([]string, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	iter, err := repo.Log(&git.LogOptions{Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var authors []string
+	err = iter.ForEach(func(c *object.Commit) error {
+		if limit > 0 && len(authors) >= limit {
+			return storer.ErrStop
+		}
+		authors = append(authors, c.Author.Name)
+		return nil
+	})
+	return authors, err
+}
+
+// branchMembershipSlack bounds the history walked from each branch tip: the
+// walk stops at commits committed this long before the oldest commit asked
+// about, since those cannot be descendants of it unless dates were rewritten.
+const branchMembershipSlack = 30 * 24 * time.Hour
+
+func (GoGitBackend) Branches(repoPath string, hashes []string) (map[string][]string, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	targets := make(map[plumbing.Hash]string, len(hashes))
+	var oldest time.Time
+	for _, h := range hashes {
+		resolved, err := repo.ResolveRevision(plumbing.Revision(h))
+		if err != nil {
+			return nil, err
+		}
+		c, err := repo.CommitObject(*resolved)
+		if err != nil {
+			return nil, err
+		}
+		targets[*resolved] = h
+		if oldest.IsZero() || c.Committer.When.Before(oldest) {
+			oldest = c.Committer.When
+		}
+	}
+	cutoff := oldest.Add(-branchMembershipSlack)
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	defer refs.Close()
+	found := make(map[string][]string, len(hashes))
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().String()
+		if ref.Type() != plumbing.HashReference || normalizeBranch(name) == "" {
+			return nil
+		}
+		tip, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return nil
+		}
+		seen := make(map[plumbing.Hash]bool)
+		queue := []*object.Commit{tip}
+		for len(queue) > 0 {
+			c := queue[0]
+			queue = queue[1:]
+			if seen[c.Hash] {
+				continue
+			}
+			seen[c.Hash] = true
+			if h, ok := targets[c.Hash]; ok {
+				found[h] = append(found[h], name)
+			}
+			if c.Committer.When.Before(cutoff) {
+				continue
+			}
+			err := c.Parents().ForEach(func(p *object.Commit) error {
+				queue = append(queue, p)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string][]string, len(hashes))
+	for _, h := range hashes {
+		out[h] = normalizeBranches(found[h])
+	}
+	return out, nil
+}
+
+func (GoGitBackend) BranchCommits(repoPath string, since time.Time) (map[string][]string, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	defer refs.Close()
+	var defaults, candidates []*plumbing.Reference
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := normalizeBranch(ref.Name().String())
+		switch {
+		case ref.Type() != plumbing.HashReference || name == "":
+		case defaultBranches[name]:
+			defaults = append(defaults, ref)
+		default:
+			candidates = append(candidates, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Walks stop at commits older than the slack, like Branches.
+	cutoff := since.Add(-branchMembershipSlack)
+	walk := func(tip plumbing.Hash, skip map[plumbing.Hash]bool, visit func(*object.Commit)) error {
+		c, err := repo.CommitObject(tip)
+		if err != nil {
+			return nil
+		}
+		seen := make(map[plumbing.Hash]bool)
+		queue := []*object.Commit{c}
+		for len(queue) > 0 {
+			c := queue[0]
+			queue = queue[1:]
+			if seen[c.Hash] || skip[c.Hash] {
+				continue
+			}
+			seen[c.Hash] = true
+			visit(c)
+			if c.Committer.When.Before(cutoff) {
+				continue
+			}
+			err := c.Parents().ForEach(func(p *object.Commit) error {
+				queue = append(queue, p)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	onDefault := make(map[plumbing.Hash]bool)
+	for _, ref := range defaults {
+		if err := walk(ref.Hash(), nil, func(c *object.Commit) { onDefault[c.Hash] = true }); err != nil {
+			return nil, err
+		}
+	}
+	out := make(map[string][]string, len(candidates))
+	for _, ref := range candidates {
+		// A tip committed before since cannot hold a newer commit
+		tip, err := repo.CommitObject(ref.Hash())
+		if err != nil || tip.Committer.When.Before(since) {
+			continue
+		}
+		var hashes []string
+		err = walk(ref.Hash(), onDefault, func(c *object.Commit) {
+			if !c.Committer.When.Before(since) {
+				hashes = append(hashes, c.Hash.String())
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		out[ref.Name().String()] = hashes
+	}
+	return out, nil
+}
+
+// WorkingTree compares the worktree with HEAD. go-git cannot read stashes,
+// so Stashes is always empty with this backend.
+func (GoGitBackend) WorkingTree(repoPath string) (*WorkingTree, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+	var headTree *object.Tree
+	if head, err := repo.Head(); err == nil {
+		if c, err := repo.CommitObject(head.Hash()); err == nil {
+			headTree, _ = c.Tree()
+		}
+	}
+
+	paths := make([]string, 0, len(status))
+	for p := range status {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	out := &WorkingTree{}
+	var untracked []string
+	for _, p := range paths {
+		st := status[p]
+		if st.Worktree == git.Untracked {
+			untracked = append(untracked, p)
+			continue
+		}
+		if st.Staging == git.Unmodified && st.Worktree == git.Unmodified {
+			continue
+		}
+		before, inHead := "", false
+		if headTree != nil {
+			if f, err := headTree.File(p); err == nil {
+				if before, err = f.Contents(); err != nil {
+					return nil, err
+				}
+				inHead = true
+			}
+		}
+		after, onDisk := "", false
+		if data, err := util.ReadFile(wt.Filesystem, p); err == nil {
+			after, onDisk = string(data), true
+		}
+		if !inHead && !onDisk {
+			continue // added to the index, then removed from disk
+		}
+		f := DiffFile{Path: p, IsNew: !inHead, IsDeleted: !onDisk, IsTest: isTestFile(p)}
+		if isBinaryText(before) || isBinaryText(after) {
+			f.IsBinary = true
+		} else {
+			applyChunks(&f, textChunks(before, after), 1)
+		}
+		out.Files = append(out.Files, f)
+	}
+	out.Files = append(out.Files, untrackedFiles(untracked)...)
+	return out, nil
+}
+
+func (GoGitBackend) FileAt(repoPath string, rev string, path string) (string, error) {
+	repo, err := openRepo(repoPath)
+	if err != nil {
+		return "", err
+	}
+	h, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return "", err
+	}
+	c, err := repo.CommitObject(*h)
+	if err != nil {
+		return "", err
+	}
+	f, err := c.File(path)
+	if err != nil {
+		return "", err
+	}
+	content, err := f.Contents()
+	if err != nil {
+		return "", err
+	}
+	// Match the exec backend, whose output is trimmed
+	return strings.TrimSpace(content), nil
+}
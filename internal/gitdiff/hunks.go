@@ -0,0 +1,210 @@
+package gitdiff
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+)
+
+// Hunk is one "@@ -a,b +c,d @@ section" block of a file's diff.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Section is the enclosing function or heading git prints after the
+	// "@@" header, if any.
+	Section string
+	Lines   []HunkLine
+}
+
+// HunkLine is a context, added or removed line of a hunk, with its line
+// number on each side. Added lines have no old line and removed lines no
+// new line.
+type HunkLine struct {
+	Op      byte // ' ', '+' or '-'
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// Edit kinds of a hunk.
+const (
+	EditAdded    = "added"    // only adds lines
+	EditRemoved  = "removed"  // only removes lines
+	EditModified = "modified" // replaces existing lines
+)
+
+// Edit tells pure additions and removals apart from modifications.
+func (h Hunk) Edit() string {
+	added, removed := false, false
+	for _, l := range h.Lines {
+		switch l.Op {
+		case '+':
+			added = true
+		case '-':
+			removed = true
+		}
+	}
+	switch {
+	case added && removed:
+		return EditModified
+	case removed:
+		return EditRemoved
+	}
+	return EditAdded
+}
+
+// hunkHeaderRe matches "@@ -12,3 +12,4 @@ func Foo()"; a missing count is 1.
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+func parseHunkHeader(line string) (Hunk, bool) {
+	m := hunkHeaderRe.FindStringSubmatch(line)
+	if m == nil {
+		return Hunk{}, false
+	}
+	count := func(s string) int {
+		if s == "" {
+			return 1
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	start := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	return Hunk{
+		OldStart: start(m[1]),
+		OldLines: count(m[2]),
+		NewStart: start(m[3]),
+		NewLines: count(m[4]),
+		Section:  m[5],
+	}, true
+}
+
+// applyChunks fills a file's changed lines and hunks from whole-file
+// chunks, as produced by go-git. Changes closer than twice the context are
+// merged into one hunk, and sections follow git's default funcname rule,
+// so the result matches git diff -U<context> for the same changes.
+func applyChunks(f *DiffFile, chunks []diff.Chunk, context int) {
+	var lines []HunkLine
+	var old []string
+	o, n := 0, 0
+	for _, c := range chunks {
+		for _, text := range chunkLines(c.Content()) {
+			switch c.Type() {
+			case diff.Equal:
+				o, n = o+1, n+1
+				lines = append(lines, HunkLine{Op: ' ', Text: text, OldLine: o, NewLine: n})
+				old = append(old, text)
+			case diff.Delete:
+				o++
+				lines = append(lines, HunkLine{Op: '-', Text: text, OldLine: o})
+				old = append(old, text)
+				f.Deletions = append(f.Deletions, text)
+			case diff.Add:
+				n++
+				lines = append(lines, HunkLine{Op: '+', Text: text, NewLine: n})
+				f.Additions = append(f.Additions, text)
+			}
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		if lines[i].Op == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk over later changes while the gap stays small
+		end := i + 1
+		for j := end; j < len(lines) && j-end <= 2*context; j++ {
+			if lines[j].Op != ' ' {
+				end = j + 1
+			}
+		}
+		start := max(i-context, 0)
+		h := Hunk{Lines: lines[start:min(end+context, len(lines))]}
+		oldBefore, newBefore := 0, 0
+		for _, l := range lines[:start] {
+			if l.Op != '+' {
+				oldBefore++
+			}
+			if l.Op != '-' {
+				newBefore++
+			}
+		}
+		for _, l := range h.Lines {
+			if l.Op != '+' {
+				h.OldLines++
+			}
+			if l.Op != '-' {
+				h.NewLines++
+			}
+		}
+		h.OldStart, h.NewStart = oldBefore, newBefore
+		if h.OldLines > 0 {
+			h.OldStart++
+		}
+		if h.NewLines > 0 {
+			h.NewStart++
+		}
+		h.Section = funcContext(old, oldBefore)
+		f.Hunks = append(f.Hunks, h)
+		i = end
+	}
+}
+
+// funcContext mirrors git's default hunk header: the closest line before
+// the hunk's first old line that starts with a letter, "_" or "$", cut to
+// 80 bytes and without trailing whitespace.
+func funcContext(old []string, before int) string {
+	for i := min(before, len(old)) - 1; i >= 0; i-- {
+		line := old[i]
+		if line == "" {
+			continue
+		}
+		if c := line[0]; c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			if len(line) > 80 {
+				line = line[:80]
+			}
+			return strings.TrimRight(line, " \t\n\r\v\f")
+		}
+	}
+	return ""
+}
+
+// trimHunks keeps the first adds added and dels removed lines of the
+// hunks, dropping hunks left without changes. Headers keep the original
+// ranges.
+func trimHunks(hunks []Hunk, adds int, dels int) []Hunk {
+	var out []Hunk
+	for _, h := range hunks {
+		var lines []HunkLine
+		changed := false
+		for _, l := range h.Lines {
+			switch l.Op {
+			case '+':
+				if adds == 0 {
+					continue
+				}
+				adds--
+				changed = true
+			case '-':
+				if dels == 0 {
+					continue
+				}
+				dels--
+				changed = true
+			}
+			lines = append(lines, l)
+		}
+		if changed {
+			h.Lines = lines
+			out = append(out, h)
+		}
+	}
+	return out
+}
@@ -0,0 +1,172 @@
+package gitdiff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IssueRef is an issue-tracker key found in commits, with its link when the
+// tracker has a URL template. Title and Status are filled in by enrichment.
+type IssueRef struct {
+	Key     string `json:"key"`
+	Tracker string `json:"tracker,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Status  string `json:"status,omitempty"`
+}
+
+// IssueTracker recognises one kind of issue key, e.g. Jira "PROJ-123" or
+// GitHub "#456".
+type IssueTracker struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// URL is a link template. {key} is replaced with the whole key, {number}
+	// with its trailing digits and {project} with the part before them
+	// ("PROJ" or "owner/repo").
+	URL string
+}
+
+// defaultIssuePatterns are used for the well-known trackers when no pattern
+// is configured. Jira's is built from its project keys instead: a generic
+// "ABC-123" pattern also matches UTF-8, SHA-256 and ISO-8601.
+var defaultIssuePatterns = map[string]string{
+	"github": `(?:\b[\w.-]+/[\w.-]+)?#\d+\b`,
+}
+
+// NewIssueTracker compiles a tracker. An empty pattern selects the default
+// for "github", or for "jira" matches the given project keys, which are
+// then required.
+func NewIssueTracker(name string, pattern string, url string, projects []string) (IssueTracker, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if pattern == "" && name == "jira" {
+		var quoted []string
+		for _, p := range projects {
+			if p = strings.TrimSpace(p); p != "" {
+				quoted = append(quoted, regexp.QuoteMeta(strings.ToUpper(p)))
+			}
+		}
+		if len(quoted) == 0 {
+			return IssueTracker{}, fmt.Errorf("issue tracker %q: set projects (e.g. PROJ, OPS) or a pattern", name)
+		}
+		pattern = `\b(?:` + strings.Join(quoted, "|") + `)-\d+\b`
+	}
+	if pattern == "" {
+		pattern = defaultIssuePatterns[name]
+	}
+	if pattern == "" {
+		return IssueTracker{}, fmt.Errorf("issue tracker %q: no pattern configured", name)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return IssueTracker{}, fmt.Errorf("issue tracker %q: %w", name, err)
+	}
+	return IssueTracker{Name: name, Pattern: re, URL: url}, nil
+}
+
+var issueNumberRe = regexp.MustCompile(`^(.*?)[-#]?(\d+)$`)
+
+// Link returns the URL for key, or "" when the tracker has no URL template.
+func (t IssueTracker) Link(key string) string {
+	if t.URL == "" {
+		return ""
+	}
+	project, number := "", key
+	if m := issueNumberRe.FindStringSubmatch(key); m != nil {
+		project, number = m[1], m[2]
+	}
+	return strings.NewReplacer("{key}", key, "{number}", number, "{project}", project).Replace(t.URL)
+}
+
+// ExtractIssues returns the issue keys in a commit's subject, body, trailers
+// and refs, in order of first appearance.
+func ExtractIssues(c Commit, trackers []IssueTracker) []IssueRef {
+	texts := []string{c.Message, c.Body}
+	for _, t := range c.Trailers {
+		texts = append(texts, t.Value)
+	}
+	texts = append(texts, c.Refs...)
+
+	var refs []IssueRef
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, tracker := range trackers {
+			for _, m := range tracker.Pattern.FindAllStringIndex(text, -1) {
+				key := text[m[0]:m[1]]
+				if seen[key] || isColorCode(text, m[0], m[1]) {
+					continue
+				}
+				seen[key] = true
+				refs = append(refs, IssueRef{Key: key, Tracker: tracker.Name, URL: tracker.Link(key)})
+			}
+		}
+	}
+	return refs
+}
+
+// colorContextWords name the CSS properties that precede colours written
+// like issue numbers, as in "color #333" or "background: #000000".
+var colorContextWords = []string{"color", "colour", "background", "fill", "stroke", "border", "shadow"}
+
+// isColorCode reports whether a bare "#123" key at text[start:end] is a CSS
+// colour: three or six digits right after a colour property. References
+// such as "fix: #123" or "refs=#456" stay issues.
+func isColorCode(text string, start int, end int) bool {
+	key := text[start:end]
+	if !strings.HasPrefix(key, "#") || (len(key) != 4 && len(key) != 7) {
+		return false
+	}
+	fields := strings.Fields(strings.ToLower(text[:start]))
+	if len(fields) == 0 {
+		return false
+	}
+	last := strings.TrimRight(fields[len(fields)-1], ":=")
+	for _, w := range colorContextWords {
+		if strings.Contains(last, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueKeys returns the keys of refs.
+func IssueKeys(refs []IssueRef) []string {
+	var keys []string
+	for _, r := range refs {
+		keys = append(keys, r.Key)
+	}
+	return keys
+}
+
+// EnrichedIssues returns the refs that have tracker details.
+func EnrichedIssues(refs []IssueRef) []IssueRef {
+	var out []IssueRef
+	for _, r := range refs {
+		if r.Title != "" || r.Status != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ApplyIssues sets each commit task's issues to the union of the issues of its
+// commits. Manual tasks keep the issues they already have.
+func ApplyIssues(tasks []TaskChange, byCommit map[string][]IssueRef) []TaskChange {
+	for i := range tasks {
+		if tasks[i].IsManual {
+			continue
+		}
+		var refs []IssueRef
+		seen := make(map[string]bool)
+		for _, hash := range tasks[i].Commits {
+			for _, r := range byCommit[shortHash(hash)] {
+				if !seen[r.Key] {
+					seen[r.Key] = true
+					refs = append(refs, r)
+				}
+			}
+		}
+		tasks[i].Issues = refs
+	}
+	return tasks
+}
@@ -0,0 +1,102 @@
+package gitdiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testTrackers(t *testing.T) []IssueTracker {
+	t.Helper()
+	jira, err := NewIssueTracker("jira", "", "https://acme.atlassian.net/browse/{key}", []string{"PROJ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	github, err := NewIssueTracker("github", "", "https://github.com/acme/app/issues/{number}", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []IssueTracker{jira, github}
+}
+
+func TestExtractIssues(t *testing.T) {
+	c := Commit{
+		Message:  "PROJ-12: retry webhook delivery (UTF-8 safe)",
+		Body:     "Also touches PROJ-12 and OPS-3.\n\nFixes #456",
+		Trailers: []Trailer{{Key: "Fixes", Value: "#456"}},
+		Refs:     []string{"feature/PROJ-99-retries"},
+	}
+	got := ExtractIssues(c, testTrackers(t))
+	want := []IssueRef{
+		{Key: "PROJ-12", Tracker: "jira", URL: "https://acme.atlassian.net/browse/PROJ-12"},
+		{Key: "#456", Tracker: "github", URL: "https://github.com/acme/app/issues/456"},
+		{Key: "PROJ-99", Tracker: "jira", URL: "https://acme.atlassian.net/browse/PROJ-99"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected issues:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestExtractIssuesSkipsLookalikes(t *testing.T) {
+	c := Commit{
+		Message: "Use SHA-256 and UTF-8 for ISO-8601 stamps over TLS-1",
+		Body:    "Set the header color #333, background: #000000, border:#123456 and fill=#111; see #333 and (#12)",
+	}
+	got := IssueKeys(ExtractIssues(c, testTrackers(t)))
+	want := []string{"#333", "#12"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected issues: got %v, want %v", got, want)
+	}
+
+	// Conventional-commit subjects and trailers keep their references.
+	c = Commit{Message: "fix: #123 handle nil", Body: "refs=#456\nCloses: #789;"}
+	got = IssueKeys(ExtractIssues(c, testTrackers(t)))
+	want = []string{"#123", "#456", "#789"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected issues: got %v, want %v", got, want)
+	}
+
+	if _, err := NewIssueTracker("jira", "", "", nil); err == nil {
+		t.Fatal("expected Jira without project keys or a pattern to be rejected")
+	}
+	custom, err := NewIssueTracker("jira", `\b[A-Z]{2,}-\d+\b`, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := IssueKeys(ExtractIssues(Commit{Message: "PROJ-1"}, []IssueTracker{custom})); !reflect.DeepEqual(got, []string{"PROJ-1"}) {
+		t.Fatalf("expected an explicit Jira pattern to be used, got %v", got)
+	}
+}
+
+func TestIssueTrackerLink(t *testing.T) {
+	tracker := IssueTracker{Name: "github", URL: "https://github.com/{project}/issues/{number}"}
+	if got := tracker.Link("acme/api#7"); got != "https://github.com/acme/api/issues/7" {
+		t.Fatalf("unexpected link: %s", got)
+	}
+	if got := (IssueTracker{Name: "jira"}).Link("PROJ-1"); got != "" {
+		t.Fatalf("expected no link without a URL template, got %s", got)
+	}
+	if _, err := NewIssueTracker("linear", "", "", nil); err == nil {
+		t.Fatal("expected an error for an unknown tracker without a pattern")
+	}
+	if _, err := NewIssueTracker("jira", "([", "", nil); err == nil {
+		t.Fatal("expected an error for an invalid pattern")
+	}
+}
+
+func TestApplyIssues(t *testing.T) {
+	byCommit := map[string][]IssueRef{
+		"aaaaaaa": {{Key: "PROJ-1"}},
+		"bbbbbbb": {{Key: "PROJ-1"}, {Key: "#2"}},
+	}
+	tasks := []TaskChange{
+		{TaskIntent: "retries", Commits: []string{"aaaaaaa1234", "bbbbbbb"}},
+		{TaskIntent: "call with QA", IsManual: true, Issues: []IssueRef{{Key: "QA-5"}}},
+	}
+	tasks = ApplyIssues(tasks, byCommit)
+	if got := IssueKeys(tasks[0].Issues); !reflect.DeepEqual(got, []string{"PROJ-1", "#2"}) {
+		t.Fatalf("unexpected commit task issues: %v", got)
+	}
+	if got := IssueKeys(tasks[1].Issues); !reflect.DeepEqual(got, []string{"QA-5"}) {
+		t.Fatalf("manual task issues should be kept, got %v", got)
+	}
+}
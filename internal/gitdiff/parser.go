@@ -2,16 +2,191 @@ package gitdiff
 
 import (
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Commit struct {
-	Hash    string
-	Message string
+	Hash    string // abbreviated to 7 characters; used as the key across stages
+	Message string // subject line
 	Files   []DiffFile
+
+	FullHash    string
+	Body        string
+	Author      Person
+	Committer   Person
+	AuthoredAt  time.Time
+	CommittedAt time.Time
+	Parents     []string // full hashes
+	Refs        []string // branch and tag names pointing at the commit
+	Branches    []string // branches containing the commit, set by GenerateFacts
+	Trailers    []Trailer
+	CoAuthors   []Person
+}
+
+// Person is a commit author, committer or co-author.
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Trailer is a "Key: value" line from the end of a commit message, or an
+// issue keyword line such as "Fixes #123".
+type Trailer struct {
+	Key   string
+	Value string
 }
 
+// FullMessage returns the subject and body.
+func (c Commit) FullMessage() string {
+	if c.Body == "" {
+		return c.Message
+	}
+	return c.Message + "\n\n" + c.Body
+}
+
+const (
+	recordSep = "\x1e"
+	fieldSep  = "\x1f"
+)
+
+// logRecordFormat is the git log --format used by ParseGitLog. Each commit
+// starts with a record separator and its fields are unit-separated, so
+// message bodies that contain "commit " or blank lines parse safely. The
+// patch from -p follows the last separator.
+const logRecordFormat = "%x1e%H%x1f%P%x1f%D%x1f%an%x1f%ae%x1f%at%x1f%cn%x1f%ce%x1f%ct%x1f%s%x1f%b%x1f"
+
+const logRecordFields = 11
+
+// ParseGitLog parses git log output produced with logRecordFormat. Output
+// in the older "commit %h%n%s" format is still accepted.
 func ParseGitLog(raw string) []Commit {
+	if !strings.Contains(raw, recordSep) {
+		return parseLegacyLog(raw)
+	}
+	var commits []Commit
+	for _, record := range strings.Split(raw, recordSep) {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		fields := strings.SplitN(record, fieldSep, logRecordFields+1)
+		if len(fields) < logRecordFields {
+			continue
+		}
+		patch := ""
+		if len(fields) > logRecordFields {
+			patch = fields[logRecordFields]
+		}
+		c := Commit{
+			FullHash:    strings.TrimSpace(fields[0]),
+			Parents:     strings.Fields(fields[1]),
+			Refs:        parseRefs(fields[2]),
+			Author:      Person{Name: fields[3], Email: fields[4]},
+			AuthoredAt:  parseUnix(fields[5]),
+			Committer:   Person{Name: fields[6], Email: fields[7]},
+			CommittedAt: parseUnix(fields[8]),
+			Message:     strings.TrimSpace(fields[9]),
+			Body:        strings.TrimSpace(fields[10]),
+			Files:       parseFiles(strings.Split(patch, "\n")),
+		}
+		c.Hash = shortHash(c.FullHash)
+		c.Trailers, c.CoAuthors = ParseTrailers(c.Body)
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func parseUnix(s string) time.Time {
+	sec, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// parseRefs parses git's %D decoration, e.g. "HEAD -> main, origin/main, tag: v1.0".
+func parseRefs(raw string) []string {
+	var refs []string
+	for _, rp := range strings.Split(raw, ",") {
+		rp = strings.TrimSpace(rp)
+		rp = strings.TrimPrefix(rp, "HEAD -> ")
+		rp = strings.TrimPrefix(rp, "tag: ")
+		if rp != "" {
+			refs = append(refs, rp)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+var (
+	trailerRe  = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9-]*):\s+(.+)$`)
+	keywordRe  = regexp.MustCompile(`^((?i:fix(?:e[sd])?|close[sd]?|resolve[sd]?|refs?))\s+((?:[\w.-]+/[\w.-]+)?#\d+|[A-Z][A-Z0-9]+-\d+)\b`)
+	personRe   = regexp.MustCompile(`^(.*?)\s*<([^>]*)>\s*$`)
+	coAuthorRe = regexp.MustCompile(`(?i)^co-authored-by$`)
+	blankRe    = regexp.MustCompile(`\n\s*\n`)
+)
+
+// ParseTrailers returns the trailers in the last paragraph of a commit body,
+// plus issue keyword lines ("Fixes #123") anywhere in it, and the people
+// named in Co-authored-by trailers.
+func ParseTrailers(body string) ([]Trailer, []Person) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, nil
+	}
+	var trailers []Trailer
+	var coAuthors []Person
+
+	paragraphs := blankRe.Split(body, -1)
+	last := paragraphs[len(paragraphs)-1]
+	lastIsTrailers := true
+	for _, line := range strings.Split(last, "\n") {
+		if !trailerRe.MatchString(strings.TrimSpace(line)) {
+			lastIsTrailers = false
+			break
+		}
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if m := keywordRe.FindStringSubmatch(line); m != nil {
+			trailers = append(trailers, Trailer{Key: capitalizeWord(m[1]), Value: m[2]})
+		}
+	}
+	if lastIsTrailers {
+		for _, line := range strings.Split(last, "\n") {
+			m := trailerRe.FindStringSubmatch(strings.TrimSpace(line))
+			t := Trailer{Key: m[1], Value: strings.TrimSpace(m[2])}
+			trailers = append(trailers, t)
+			if coAuthorRe.MatchString(t.Key) {
+				if pm := personRe.FindStringSubmatch(t.Value); pm != nil {
+					coAuthors = append(coAuthors, Person{Name: pm[1], Email: pm[2]})
+				} else {
+					coAuthors = append(coAuthors, Person{Name: t.Value})
+				}
+			}
+		}
+	}
+	return trailers, coAuthors
+}
+
+func capitalizeWord(s string) string {
+	s = strings.ToLower(s)
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// parseLegacyLog parses "commit %h%n%s" output followed by a patch.
+func parseLegacyLog(raw string) []Commit {
 	var commits []Commit
 
 	// Normalize input to ensure we can split reliably
@@ -54,22 +229,28 @@ func ParseGitLog(raw string) []Commit {
 	return commits
 }
 
+// diffHeaderRe matches "diff --git a/path b/path".
+var diffHeaderRe = regexp.MustCompile(`^diff --git a/(.*) b/(.*)`)
+
+var binaryRe = regexp.MustCompile(`^Binary files .* differ$`)
+
+// parseFiles parses the file sections of a unified diff. Lines inside a
+// hunk are read by the hunk's line counts, so a removed line that starts
+// with "--" is not mistaken for a file header.
 func parseFiles(lines []string) []DiffFile {
 	var files []DiffFile
 	var currentFile *DiffFile
-
-	// We look for "diff --git a/path b/path"
-	diffRe := regexp.MustCompile(`^diff --git a/(.*) b/(.*)`)
-	newFileRe := regexp.MustCompile(`^new file mode`)
-	deletedFileRe := regexp.MustCompile(`^deleted file mode`)
+	var hunk *Hunk
+	oldLine, newLine, oldLeft, newLeft := 0, 0, 0, 0
 
 	for _, line := range lines {
 		if strings.HasPrefix(line, "diff --git") {
 			if currentFile != nil {
 				files = append(files, *currentFile)
 			}
+			hunk = nil
 
-			matches := diffRe.FindStringSubmatch(line)
+			matches := diffHeaderRe.FindStringSubmatch(line)
 			path := ""
 			if len(matches) > 2 {
 				path = matches[2] // Use b/path
@@ -92,18 +273,64 @@ func parseFiles(lines []string) []DiffFile {
 			continue
 		}
 
-		if newFileRe.MatchString(line) {
-			currentFile.IsNew = true
-		}
-		if deletedFileRe.MatchString(line) {
-			currentFile.IsDeleted = true
+		if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				newLine++
+				newLeft--
+				hunk.Lines = append(hunk.Lines, HunkLine{Op: '+', Text: line[1:], NewLine: newLine})
+				currentFile.Additions = append(currentFile.Additions, line[1:])
+			case strings.HasPrefix(line, "-"):
+				oldLine++
+				oldLeft--
+				hunk.Lines = append(hunk.Lines, HunkLine{Op: '-', Text: line[1:], OldLine: oldLine})
+				currentFile.Deletions = append(currentFile.Deletions, line[1:])
+			case strings.HasPrefix(line, " ") || line == "":
+				oldLine, newLine = oldLine+1, newLine+1
+				oldLeft, newLeft = oldLeft-1, newLeft-1
+				hunk.Lines = append(hunk.Lines, HunkLine{Op: ' ', Text: strings.TrimPrefix(line, " "), OldLine: oldLine, NewLine: newLine})
+			}
+			continue
 		}
 
-		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
-			currentFile.Additions = append(currentFile.Additions, strings.TrimPrefix(line, "+"))
+		if h, ok := parseHunkHeader(line); ok {
+			currentFile.Hunks = append(currentFile.Hunks, h)
+			hunk = &currentFile.Hunks[len(currentFile.Hunks)-1]
+			oldLine, newLine = h.OldStart, h.NewStart
+			if h.OldLines > 0 {
+				oldLine--
+			}
+			if h.NewLines > 0 {
+				newLine--
+			}
+			oldLeft, newLeft = h.OldLines, h.NewLines
+			continue
 		}
-		if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
-			currentFile.Deletions = append(currentFile.Deletions, strings.TrimPrefix(line, "-"))
+
+		switch {
+		case strings.HasPrefix(line, "new file mode"):
+			currentFile.IsNew = true
+		case strings.HasPrefix(line, "deleted file mode"):
+			currentFile.IsDeleted = true
+		case binaryRe.MatchString(line):
+			currentFile.IsBinary = true
+		case strings.HasPrefix(line, "rename from "):
+			currentFile.IsRename = true
+			currentFile.OldPath = unquotePath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "copy from "):
+			currentFile.IsCopy = true
+			currentFile.OldPath = unquotePath(strings.TrimPrefix(line, "copy from "))
+		case strings.HasPrefix(line, "rename to "):
+			currentFile.Path = unquotePath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "copy to "):
+			currentFile.Path = unquotePath(strings.TrimPrefix(line, "copy to "))
+		case strings.HasPrefix(line, "similarity index "):
+			currentFile.Similarity, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(line, "similarity index "), "%"))
+		case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+			// Changed lines without a hunk header
+			currentFile.Additions = append(currentFile.Additions, line[1:])
+		case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+			currentFile.Deletions = append(currentFile.Deletions, line[1:])
 		}
 	}
 
@@ -113,3 +340,14 @@ func parseFiles(lines []string) []DiffFile {
 
 	return files
 }
+
+// unquotePath undoes git's quoting of paths with special characters, as in
+// rename from "caf\303\251.go".
+func unquotePath(p string) string {
+	if strings.HasPrefix(p, `"`) {
+		if u, err := strconv.Unquote(p); err == nil {
+			return u
+		}
+	}
+	return p
+}
@@ -0,0 +1,137 @@
+package gitdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGitLogRecordFormat(t *testing.T) {
+	raw := "\x1e" + "0123456789abcdef0123456789abcdef01234567\x1faaaa bbbb\x1fHEAD -> main, tag: v1.2, origin/main\x1f" +
+		"Ana Souza\x1fana@example.com\x1f1770285600\x1fGitHub\x1fnoreply@github.com\x1f1770289200\x1f" +
+		"Add retries\x1fThis body mentions\ncommit deadbeef on its own line.\n\nFixes #42\n\nCo-authored-by: Bruno Lima <bruno@example.com>\nReviewed-by: Carla\n\x1f\n" +
+		"diff --git a/retry.go b/retry.go\nnew file mode 100644\n--- /dev/null\n+++ b/retry.go\n@@ -0,0 +1 @@\n+package retry\n" +
+		"\x1e" + "fedcba9876543210fedcba9876543210fedcba98\x1f\x1f\x1fAna Souza\x1fana@example.com\x1f1770199200\x1fAna Souza\x1fana@example.com\x1f1770199200\x1fInitial\x1f\x1f"
+
+	commits := ParseGitLog(raw)
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d: %+v", len(commits), commits)
+	}
+	c := commits[0]
+	if c.Hash != "0123456" || c.Message != "Add retries" || c.Author.Email != "ana@example.com" || c.Committer.Name != "GitHub" {
+		t.Fatalf("unexpected commit: %+v", c)
+	}
+	if c.AuthoredAt.Unix() != 1770285600 || c.CommittedAt.Unix() != 1770289200 {
+		t.Fatalf("unexpected timestamps: %v %v", c.AuthoredAt, c.CommittedAt)
+	}
+	if !reflect.DeepEqual(c.Parents, []string{"aaaa", "bbbb"}) || !reflect.DeepEqual(c.Refs, []string{"main", "origin/main", "v1.2"}) {
+		t.Fatalf("unexpected parents/refs: %v %v", c.Parents, c.Refs)
+	}
+	if len(c.Files) != 1 || c.Files[0].Path != "retry.go" || !c.Files[0].IsNew {
+		t.Fatalf("unexpected files: %+v", c.Files)
+	}
+	wantTrailers := []Trailer{
+		{Key: "Fixes", Value: "#42"},
+		{Key: "Co-authored-by", Value: "Bruno Lima <bruno@example.com>"},
+		{Key: "Reviewed-by", Value: "Carla"},
+	}
+	if !reflect.DeepEqual(c.Trailers, wantTrailers) {
+		t.Fatalf("unexpected trailers: %+v", c.Trailers)
+	}
+	if !reflect.DeepEqual(c.CoAuthors, []Person{{Name: "Bruno Lima", Email: "bruno@example.com"}}) {
+		t.Fatalf("unexpected co-authors: %+v", c.CoAuthors)
+	}
+
+	root := commits[1]
+	if root.Message != "Initial" || len(root.Parents) != 0 || len(root.Files) != 0 {
+		t.Fatalf("unexpected root commit: %+v", root)
+	}
+}
+
+func TestParseGitLogLegacyFormat(t *testing.T) {
+	raw := "commit abc1234\nFix login\ndiff --git a/login.go b/login.go\n--- a/login.go\n+++ b/login.go\n@@ -1 +1 @@\n-old\n+new\n"
+	commits := ParseGitLog(raw)
+	if len(commits) != 1 || commits[0].Hash != "abc1234" || commits[0].Message != "Fix login" {
+		t.Fatalf("unexpected legacy parse: %+v", commits)
+	}
+	if f := commits[0].Files; len(f) != 1 || f[0].Additions[0] != "new" || f[0].Deletions[0] != "old" {
+		t.Fatalf("unexpected legacy files: %+v", f)
+	}
+}
+
+func TestParseTrailersIgnoresProse(t *testing.T) {
+	trailers, coAuthors := ParseTrailers("Note: this is prose\nand continues here.")
+	if len(trailers) != 0 || len(coAuthors) != 0 {
+		t.Fatalf("expected no trailers, got %+v %+v", trailers, coAuthors)
+	}
+	trailers, _ = ParseTrailers("Closes PROJ-12 and more")
+	if len(trailers) != 1 || trailers[0] != (Trailer{Key: "Closes", Value: "PROJ-12"}) {
+		t.Fatalf("unexpected keyword trailer: %+v", trailers)
+	}
+}
+
+func TestParseFilesHunks(t *testing.T) {
+	patch := "diff --git a/pay.go b/pay.go\n--- a/pay.go\n+++ b/pay.go\n" +
+		"@@ -3,3 +3,4 @@ func Pay(amount int) error {\n" +
+		" \tif amount < 0 {\n" +
+		"--- a comment that looks like a header\n" +
+		"+\t\treturn ErrNegative\n" +
+		"+\t}\n" +
+		" \treturn nil\n" +
+		"@@ -20 +21,0 @@ func Refund() {\n" +
+		"-\tlog.Println(\"refund\")\n" +
+		"diff --git a/logo.png b/logo.png\nindex 1111111..2222222 100644\nBinary files a/logo.png and b/logo.png differ\n"
+
+	files := parseFiles(strings.Split(patch, "\n"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 files, got %+v", files)
+	}
+	f := files[0]
+	if !reflect.DeepEqual(f.Deletions, []string{"-- a comment that looks like a header", "\tlog.Println(\"refund\")"}) || len(f.Additions) != 2 {
+		t.Fatalf("unexpected changed lines: %+v %+v", f.Additions, f.Deletions)
+	}
+	if len(f.Hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %+v", f.Hunks)
+	}
+	h := f.Hunks[0]
+	if h.OldStart != 3 || h.OldLines != 3 || h.NewStart != 3 || h.NewLines != 4 || h.Section != "func Pay(amount int) error {" {
+		t.Fatalf("unexpected header: %+v", h)
+	}
+	wantLines := []HunkLine{
+		{Op: ' ', Text: "\tif amount < 0 {", OldLine: 3, NewLine: 3},
+		{Op: '-', Text: "-- a comment that looks like a header", OldLine: 4},
+		{Op: '+', Text: "\t\treturn ErrNegative", NewLine: 4},
+		{Op: '+', Text: "\t}", NewLine: 5},
+		{Op: ' ', Text: "\treturn nil", OldLine: 5, NewLine: 6},
+	}
+	if !reflect.DeepEqual(h.Lines, wantLines) || h.Edit() != EditModified {
+		t.Fatalf("unexpected hunk lines: %+v (%s)", h.Lines, h.Edit())
+	}
+	if r := f.Hunks[1]; r.OldStart != 20 || r.OldLines != 1 || r.NewStart != 21 || r.NewLines != 0 || r.Edit() != EditRemoved {
+		t.Fatalf("unexpected removal hunk: %+v", r)
+	}
+	if b := files[1]; b.Path != "logo.png" || !b.IsBinary || len(b.Hunks) != 0 {
+		t.Fatalf("unexpected binary file: %+v", b)
+	}
+}
+
+func TestParseFilesRenamesAndCopies(t *testing.T) {
+	patch := "diff --git a/old/pay.go b/billing/pay.go\nsimilarity index 92%\nrename from old/pay.go\nrename to billing/pay.go\n" +
+		"--- a/old/pay.go\n+++ b/billing/pay.go\n@@ -1 +1 @@\n-package old\n+package billing\n" +
+		"diff --git a/a.go b/b.go\nsimilarity index 100%\ncopy from a.go\ncopy to b.go\n" +
+		"diff --git \"a/caf\\303\\251.go\" \"b/menu/caf\\303\\251.go\"\nsimilarity index 100%\nrename from \"caf\\303\\251.go\"\nrename to \"menu/caf\\303\\251.go\"\n"
+
+	files := parseFiles(strings.Split(patch, "\n"))
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %+v", files)
+	}
+	if f := files[0]; f.Path != "billing/pay.go" || f.OldPath != "old/pay.go" || !f.IsRename || f.Similarity != 92 || f.IsNew || len(f.Hunks) != 1 {
+		t.Fatalf("unexpected rename: %+v", f)
+	}
+	if f := files[1]; f.Path != "b.go" || f.OldPath != "a.go" || !f.IsCopy || f.IsRename || f.Similarity != 100 {
+		t.Fatalf("unexpected copy: %+v", f)
+	}
+	if f := files[2]; f.Path != "menu/café.go" || f.OldPath != "café.go" {
+		t.Fatalf("unexpected quoted rename: %+v", f)
+	}
+}
@@ -0,0 +1,232 @@
+package gitdiff
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SecretFinding is a possible secret in a commit's added lines or message.
+// The secret itself is never kept: only where it was and what it looked
+// like. File is empty for a commit message.
+type SecretFinding struct {
+	Commit string `json:"commit"`
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Kind   string `json:"kind"`
+}
+
+// Where reports the location of a finding, e.g. "config/prod.go:12".
+func (f SecretFinding) Where() string {
+	switch {
+	case f.File == "":
+		return "commit message"
+	case f.Line > 0:
+		return f.File + ":" + strconv.Itoa(f.Line)
+	}
+	return f.File
+}
+
+type secretPattern struct {
+	kind string
+	re   *regexp.Regexp
+	// group is the submatch holding the secret; 0 masks the whole match.
+	group int
+}
+
+// secretPatterns are well-known credential formats, most specific first.
+var secretPatterns = []secretPattern{
+	{kind: "private_key", re: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{kind: "aws_access_key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "github_token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{kind: "gitlab_token", re: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{kind: "slack_token", re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{kind: "slack_webhook", re: regexp.MustCompile(`https://hooks\.slack\.com/services/[A-Za-z0-9/]+`)},
+	{kind: "stripe_key", re: regexp.MustCompile(`\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}\b`)},
+	{kind: "google_api_key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{kind: "llm_api_key", re: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{kind: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{kind: "url_password", re: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:([^\s:/@]{3,})@`), group: 1},
+	{kind: "assigned_secret", re: regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key)\w*["']?\s*[:=]\s*["']([^"'\s]{8,})["']`), group: 1},
+}
+
+// entropyTokenRe finds base64-like runs to check for entropy.
+var entropyTokenRe = regexp.MustCompile(`[A-Za-z0-9+/_-]{20,}={0,2}`)
+
+const redactedPrefix = "[REDACTED:"
+
+func redacted(kind string) string {
+	return redactedPrefix + kind + "]"
+}
+
+// RedactSecrets masks possible secrets in text, such as a diff or a line,
+// and returns the kinds it masked, in order. Known credential formats are
+// masked first, then long random-looking strings.
+func RedactSecrets(text string) (string, []string) {
+	var kinds []string
+	for _, p := range secretPatterns {
+		if !p.re.MatchString(text) {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range p.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[2*p.group], m[2*p.group+1]
+			secret := text[start:end]
+			if start < last || strings.Contains(text[m[0]:m[1]], redactedPrefix) || isPlaceholder(secret) {
+				continue
+			}
+			b.WriteString(text[last:start])
+			b.WriteString(redacted(p.kind))
+			last = end
+			kinds = append(kinds, p.kind)
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range entropyTokenRe.FindAllStringIndex(text, -1) {
+		token := text[m[0]:m[1]]
+		if !looksRandom(token) || isChecksum(text[:m[0]], token) {
+			continue
+		}
+		b.WriteString(text[last:m[0]])
+		b.WriteString(redacted("high_entropy"))
+		last = m[1]
+		kinds = append(kinds, "high_entropy")
+	}
+	b.WriteString(text[last:])
+	text = b.String()
+	return text, kinds
+}
+
+// checksumPrefixes introduce base64 checksums, as in go.sum ("h1:") and
+// subresource integrity hashes in lockfiles ("sha512-").
+var checksumPrefixes = []string{"h1:", "sha1-", "sha256-", "sha384-", "sha512-"}
+
+// isChecksum reports whether a token, or the text before it, starts with a
+// checksum prefix.
+func isChecksum(before string, token string) bool {
+	for _, p := range checksumPrefixes {
+		if strings.HasSuffix(before, p) || strings.HasPrefix(token, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPlaceholder reports whether an assigned value is a reference rather
+// than a secret, e.g. ${DB_PASSWORD}, {{ .Token }} or <your-token>.
+func isPlaceholder(value string) bool {
+	return strings.ContainsAny(value[:1], "$<{%") || strings.Contains(strings.ToLower(value), "example")
+}
+
+// looksRandom reports whether a token looks like a generated secret: mixed
+// case letters and digits that switch class often, unlike identifiers made
+// of words, with high Shannon entropy for its length. Hex strings such as
+// commit hashes and checksums are not flagged.
+func looksRandom(token string) bool {
+	var upper, lower, digit bool
+	hex := true
+	// Separators such as "-" and "_" join words; only letters and
+	// digits count towards switches.
+	alnum, switches := 0, 0
+	prev := -1
+	for _, r := range token {
+		class := -1
+		switch {
+		case unicode.IsUpper(r):
+			upper, class = true, 0
+		case unicode.IsLower(r):
+			lower, class = true, 1
+		case unicode.IsDigit(r):
+			digit, class = true, 2
+		}
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			hex = false
+		}
+		if class < 0 {
+			continue
+		}
+		if prev >= 0 && class != prev {
+			switches++
+		}
+		prev = class
+		alnum++
+	}
+	if hex || !upper || !lower || !digit || float64(switches) < 0.3*float64(alnum-1) {
+		return false
+	}
+	threshold := min(0.85*math.Log2(float64(len(token))), 4.5)
+	return shannonEntropy(token) >= threshold
+}
+
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// redactFiles masks secrets in the changed lines of files, in place, and
+// returns the findings in added lines, with line numbers when the files
+// have hunks.
+func redactFiles(commit string, files []DiffFile) []SecretFinding {
+	var findings []SecretFinding
+	for i := range files {
+		f := &files[i]
+		fromHunks := len(f.Hunks) > 0
+		for h := range f.Hunks {
+			for l := range f.Hunks[h].Lines {
+				line := &f.Hunks[h].Lines[l]
+				var kinds []string
+				line.Text, kinds = RedactSecrets(line.Text)
+				if line.Op == '+' {
+					for _, k := range kinds {
+						findings = append(findings, SecretFinding{Commit: commit, File: f.Path, Line: line.NewLine, Kind: k})
+					}
+				}
+			}
+		}
+		for a := range f.Additions {
+			var kinds []string
+			f.Additions[a], kinds = RedactSecrets(f.Additions[a])
+			if !fromHunks {
+				for _, k := range kinds {
+					findings = append(findings, SecretFinding{Commit: commit, File: f.Path, Kind: k})
+				}
+			}
+		}
+		for d := range f.Deletions {
+			f.Deletions[d], _ = RedactSecrets(f.Deletions[d])
+		}
+	}
+	return findings
+}
+
+// redactCommit masks secrets in a commit's message and changed lines, in
+// place, and returns what it found.
+func redactCommit(c *Commit) []SecretFinding {
+	var findings []SecretFinding
+	var kinds, bodyKinds []string
+	c.Message, kinds = RedactSecrets(c.Message)
+	c.Body, bodyKinds = RedactSecrets(c.Body)
+	for _, k := range append(kinds, bodyKinds...) {
+		findings = append(findings, SecretFinding{Commit: c.Hash, Kind: k})
+	}
+	for i := range c.Trailers {
+		c.Trailers[i].Value, _ = RedactSecrets(c.Trailers[i].Value)
+	}
+	return append(findings, redactFiles(c.Hash, c.Files)...)
+}
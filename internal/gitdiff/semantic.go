@@ -2,7 +2,11 @@ package gitdiff
 
 import (
 	"encoding/json"
+	"fmt"
+	"md2slack/internal/goast"
 	"md2slack/internal/hintdetector"
+	"md2slack/internal/outline"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -13,14 +17,28 @@ type DiffFile struct {
 	IsNew     bool
 	IsDeleted bool
 	IsTest    bool
+	IsBinary  bool
 	Additions []string
 	Deletions []string
+	// Hunks hold the changed lines with their line numbers, context and
+	// enclosing function. Additions and Deletions list the same lines.
+	Hunks []Hunk
+
+	// OldPath is the source of a rename or copy. Similarity is git's
+	// similarity index between the two versions, from 0 to 100.
+	OldPath    string
+	IsRename   bool
+	IsCopy     bool
+	Similarity int
+
+	OmittedLines int // changed lines cut by a DiffFilter line cap
 }
 
 type SignalType string
 
 const (
 	SignalNewFile       SignalType = "new_file"
+	SignalRename        SignalType = "rename"
 	SignalTestAdded     SignalType = "test_added"
 	SignalTestModified  SignalType = "test_modified"
 	SignalTimeoutChange SignalType = "timeout_change"
@@ -28,12 +46,65 @@ const (
 	SignalRouteChange   SignalType = "route_change"
 	SignalSchemaChange  SignalType = "schema_change"
 	SignalLogicChange   SignalType = "logic_change"
+
+	// Declaration-level signals from parsing the changed code, see
+	// ExtractSignalsAt.
+	SignalFuncAdded       SignalType = goast.FuncAdded
+	SignalFuncRemoved     SignalType = goast.FuncRemoved
+	SignalFuncChanged     SignalType = goast.FuncChanged
+	SignalAPIChange       SignalType = goast.APIChange
+	SignalTypeAdded       SignalType = goast.TypeAdded
+	SignalFieldChange     SignalType = goast.FieldChange
+	SignalTypeChange      SignalType = outline.TypeChange
+	SignalComponentChange SignalType = outline.ComponentChange
 )
 
 type Signal struct {
-	File  string       `json:"file"`
-	Types []SignalType `json:"types"`
-	Hints []string     `json:"hints,omitempty"`
+	File      string       `json:"file"`
+	Types     []SignalType `json:"types"`
+	Hints     []string     `json:"hints,omitempty"`
+	Locations []Location   `json:"locations,omitempty"`
+	// Weights are the confidence of each type, from 0 to 1: the highest
+	// weight of the detectors that reported it.
+	Weights map[SignalType]float64 `json:"weights,omitempty"`
+	// Counts are how often each type was reported: matching lines for
+	// line detectors, declarations for parsed code.
+	Counts map[SignalType]int `json:"counts,omitempty"`
+	// CodeHints are the Hints that quote the changed code, from rules whose
+	// hint uses capture groups. They are left out where code must not go.
+	CodeHints []string `json:"-"`
+}
+
+// structuralWeight is the confidence of signals read from the diff itself
+// or from parsed code rather than matched by a line detector.
+const structuralWeight = 1.0
+
+// Location is an added line where a line detector fired, with the
+// function around it and whether its hunk added code or modified existing
+// code (EditAdded or EditModified).
+type Location struct {
+	Type    SignalType `json:"type"`
+	Line    int        `json:"line"`
+	Section string     `json:"section,omitempty"`
+	Edit    string     `json:"edit"`
+}
+
+// maxSignalLocations caps the locations kept per file.
+const maxSignalLocations = 5
+
+// addedLocations returns the location of each added line, in the order of
+// Additions.
+func (f DiffFile) addedLocations() []Location {
+	var locs []Location
+	for _, h := range f.Hunks {
+		edit := h.Edit()
+		for _, l := range h.Lines {
+			if l.Op == '+' {
+				locs = append(locs, Location{Line: l.NewLine, Section: h.Section, Edit: edit})
+			}
+		}
+	}
+	return locs
 }
 
 type SemanticChange struct {
@@ -60,6 +131,14 @@ type CommitSemantic struct {
 	Signals      []Signal `json:"signals,omitempty"`
 	FilesTouched int      `json:"files_touched"`
 	TouchesTests bool     `json:"touches_tests"`
+	// SessionMinutes is the work-session time attributed to the commit.
+	SessionMinutes int `json:"session_minutes,omitempty"`
+	// IssueKeys are the issue-tracker keys referenced by the commit.
+	IssueKeys []string `json:"issue_keys,omitempty"`
+	// Branches are the branches containing the commit; Branch is the
+	// feature branch it was most likely written on.
+	Branches []string `json:"branches,omitempty"`
+	Branch   string   `json:"branch,omitempty"`
 }
 
 // Pipeline Stage 1 Output
@@ -70,6 +149,15 @@ type CommitChange struct {
 	Scope      string   `json:"scope"`
 	Signals    []string `json:"signals"`
 	Confidence float64  `json:"confidence"`
+
+	// SessionMinutes is measured from commit timestamps, not returned by the LLM.
+	SessionMinutes int `json:"session_minutes,omitempty"`
+	// IssueKeys are extracted with the configured issue patterns.
+	IssueKeys []string `json:"issue_keys,omitempty"`
+	// Issues holds the tracker title and status of enriched issue keys.
+	Issues []IssueRef `json:"issues,omitempty"`
+	// Branch is the feature branch the commit was written on, if any.
+	Branch string `json:"branch,omitempty"`
 }
 
 func (c *CommitChange) UnmarshalJSON(data []byte) error {
@@ -101,19 +189,23 @@ func (c *CommitChange) UnmarshalJSON(data []byte) error {
 
 // Pipeline Stage 2 Output
 type TaskChange struct {
-	TaskType       string   `json:"task_type"`
-	TaskIntent     string   `json:"task_intent"`             // Primary description used in UI if Title empty
-	Title          string   `json:"title,omitempty"`         // User-friendly title
-	Description    string   `json:"description,omitempty"`   // Detailed description
-	TimeEstimate   string   `json:"time_estimate,omitempty"` // String estimate like "2h"
-	Scope          string   `json:"scope"`
-	Commits        []string `json:"commits"`
-	Confidence     float64  `json:"confidence"`
-	EstimatedHours *int     `json:"estimated_hours,omitempty"`
-	TechnicalWhy   string   `json:"technical_why,omitempty"`
-	Status         string   `json:"status,omitempty"`
-	IsHistorical   bool     `json:"is_historical,omitempty"`
-	IsManual       bool     `json:"is_manual,omitempty"`
+	TaskType         string     `json:"task_type"`
+	TaskIntent       string     `json:"task_intent"`             // Primary description used in UI if Title empty
+	Title            string     `json:"title,omitempty"`         // User-friendly title
+	Description      string     `json:"description,omitempty"`   // Detailed description
+	TimeEstimate     string     `json:"time_estimate,omitempty"` // String estimate like "2h"
+	Scope            string     `json:"scope"`
+	Commits          []string   `json:"commits"`
+	Confidence       float64    `json:"confidence"`
+	EstimatedHours   *int       `json:"estimated_hours,omitempty"`
+	TechnicalWhy     string     `json:"technical_why,omitempty"`
+	Status           string     `json:"status,omitempty"`
+	IsHistorical     bool       `json:"is_historical,omitempty"`
+	IsManual         bool       `json:"is_manual,omitempty"`
+	IsWorkInProgress bool       `json:"is_work_in_progress,omitempty"` // built from uncommitted changes or stashes
+	Issues           []IssueRef `json:"issues,omitempty"`
+	Branch           string     `json:"branch,omitempty"`         // feature branch the task's commits were seeded from
+	UntestedFiles    []string   `json:"untested_files,omitempty"` // source files its commits changed without tests
 
 	// Helper methods
 	Intent string `json:"intent,omitempty"` // Alias for TaskIntent for legacy compatibility
@@ -121,14 +213,18 @@ type TaskChange struct {
 
 func (t *TaskChange) UnmarshalJSON(data []byte) error {
 	type rawTaskChange struct {
-		TaskType       interface{} `json:"task_type"`
-		TaskIntent     interface{} `json:"task_intent"`
-		Scope          interface{} `json:"scope"`
-		Commits        interface{} `json:"commits"`
-		Confidence     *float64    `json:"confidence"`
-		EstimatedHours interface{} `json:"estimated_hours"`
-		TechnicalWhy   interface{} `json:"technical_why"`
-		Status         interface{} `json:"status"`
+		TaskType       interface{}     `json:"task_type"`
+		TaskIntent     interface{}     `json:"task_intent"`
+		Scope          interface{}     `json:"scope"`
+		Commits        interface{}     `json:"commits"`
+		Confidence     *float64        `json:"confidence"`
+		EstimatedHours interface{}     `json:"estimated_hours"`
+		TechnicalWhy   interface{}     `json:"technical_why"`
+		Status         interface{}     `json:"status"`
+		Issues         json.RawMessage `json:"issues"`
+		Branch         interface{}     `json:"branch"`
+		WorkInProgress interface{}     `json:"is_work_in_progress"`
+		UntestedFiles  interface{}     `json:"untested_files"`
 	}
 
 	var raw rawTaskChange
@@ -164,6 +260,14 @@ func (t *TaskChange) UnmarshalJSON(data []byte) error {
 		t.TechnicalWhy = strings.Join(lines, "\n")
 	}
 	t.Status = strings.ToLower(strings.TrimSpace(castString(raw.Status)))
+	t.Branch = castString(raw.Branch)
+	t.IsWorkInProgress = raw.WorkInProgress == true
+	t.UntestedFiles = castStringSlice(raw.UntestedFiles)
+	if len(raw.Issues) > 0 {
+		// Issues are attached from commits, so a malformed value is dropped
+		// rather than failing the whole task.
+		_ = json.Unmarshal(raw.Issues, &t.Issues)
+	}
 
 	return nil
 }
@@ -286,48 +390,33 @@ func castIntSlice(v interface{}) []int {
 }
 
 // 2. Detection Logic
-func isTestFile(path string) bool {
-	return strings.Contains(path, "/test") ||
-		strings.Contains(path, ".spec.") ||
-		strings.Contains(path, ".test.")
-}
 
-var detectors = []hintdetector.Detector{
-	hintdetector.TimeoutDetector{},
-	hintdetector.ErrorHandlingDetector{},
-	hintdetector.SchemaDetector{},
-	hintdetector.UIDetector{},
-	hintdetector.LogicDetector{},
-	hintdetector.NextJSDetector{},
-	hintdetector.ExpressDetector{},
-	hintdetector.DrizzleDetector{},
-	hintdetector.MigrationDetector{},
-	hintdetector.TypeScriptDetector{},
-	hintdetector.AuthDetector{},
-	hintdetector.RefactorDetector{},
-	hintdetector.TestStabilityDetector{},
-	hintdetector.RetryDetector{},
-	hintdetector.StateGuardDetector{},
-	hintdetector.UXBugFixDetector{},
-	hintdetector.ORMMigrationDetector{},
-	hintdetector.CompletionFlowDetector{},
-	hintdetector.RegressionTestDetector{},
-	hintdetector.StyleAdjustmentDetector{},
-	hintdetector.StabilityGuardDetector{},
+// ExtractSignals runs the line detectors over a file's added lines. Without
+// the repository, every detector that is not disabled globally runs; see
+// DetectorsFor.
+func ExtractSignals(file DiffFile) Signal {
+	return extractSignals(file, globalDetectors(), nil, false)
 }
 
-func ExtractSignals(file DiffFile) Signal {
+func extractSignals(file DiffFile, specs []hintdetector.Spec, code []codeChange, parsed bool) Signal {
 	s := Signal{
 		File: file.Path,
 	}
 
 	seenTypes := make(map[SignalType]bool)
-	addType := func(t SignalType) {
+	addWeighted := func(t SignalType, weight float64) {
 		if !seenTypes[t] {
 			s.Types = append(s.Types, t)
 			seenTypes[t] = true
 		}
+		if s.Weights == nil {
+			s.Weights = make(map[SignalType]float64)
+			s.Counts = make(map[SignalType]int)
+		}
+		s.Weights[t] = max(s.Weights[t], weight)
+		s.Counts[t]++
 	}
+	addType := func(t SignalType) { addWeighted(t, structuralWeight) }
 
 	seenHints := make(map[string]bool)
 	addHint := func(h string) {
@@ -337,7 +426,19 @@ func ExtractSignals(file DiffFile) Signal {
 		}
 	}
 
-	if file.IsNew {
+	switch {
+	case file.IsRename:
+		// A move is a refactor, not new code
+		addType(SignalRename)
+		if file.Similarity < 100 {
+			addHint(fmt.Sprintf("moved %s to %s with edits (%d%% similar)", file.OldPath, file.Path, file.Similarity))
+		} else {
+			addHint(fmt.Sprintf("moved %s to %s", file.OldPath, file.Path))
+		}
+	case file.IsCopy:
+		addType(SignalNewFile)
+		addHint(fmt.Sprintf("copied %s from %s", file.Path, file.OldPath))
+	case file.IsNew:
 		addType(SignalNewFile)
 	}
 
@@ -349,12 +450,36 @@ func ExtractSignals(file DiffFile) Signal {
 		}
 	}
 
-	for _, line := range file.Additions {
-		for _, d := range detectors {
-			sigType, hint, found := d.Detect(line, file.Path)
+	for _, c := range code {
+		addType(c.kind)
+		addHint(c.hint)
+	}
+
+	seenLocations := make(map[Location]bool)
+	locations := file.addedLocations()
+	var lineSpecs []hintdetector.Spec
+	for _, spec := range specs {
+		if spec.AppliesTo(file.Path) && !(parsed && replacedByStructure(spec.Name)) {
+			lineSpecs = append(lineSpecs, spec)
+		}
+	}
+	for i, line := range file.Additions {
+		for _, spec := range lineSpecs {
+			sigType, hint, found := spec.Detector.Detect(line, file.Path)
 			if found {
-				addType(SignalType(sigType))
+				addWeighted(SignalType(sigType), spec.Weight)
 				addHint(hint)
+				if spec.QuotesCode && hint != "" && !slices.Contains(s.CodeHints, hint) {
+					s.CodeHints = append(s.CodeHints, hint)
+				}
+				if i < len(locations) && len(s.Locations) < maxSignalLocations {
+					loc := locations[i]
+					loc.Type = SignalType(sigType)
+					if !seenLocations[loc] {
+						s.Locations = append(s.Locations, loc)
+						seenLocations[loc] = true
+					}
+				}
 			}
 		}
 	}
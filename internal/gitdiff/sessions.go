@@ -0,0 +1,114 @@
+package gitdiff
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// SessionOptions controls how commits are clustered into work sessions.
+type SessionOptions struct {
+	// IdleGap is the longest pause between two commits of the same session.
+	IdleGap time.Duration
+	// LeadIn is the work assumed before the first commit of a session.
+	LeadIn time.Duration
+}
+
+// WorkSession is a run of commits with no pause longer than the idle gap.
+type WorkSession struct {
+	Start   time.Time // LeadIn before the first commit
+	End     time.Time // the last commit
+	Commits []string  // short hashes, oldest first
+}
+
+func (s WorkSession) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+type timedCommit struct {
+	hash string
+	at   time.Time
+}
+
+// BuildSessions clusters commits by author time. Commits without a timestamp
+// (e.g. from the legacy log format) are skipped.
+func BuildSessions(commits []Commit, opts SessionOptions) []WorkSession {
+	var timed []timedCommit
+	for _, c := range commits {
+		if c.AuthoredAt.IsZero() {
+			continue
+		}
+		timed = append(timed, timedCommit{hash: c.Hash, at: c.AuthoredAt})
+	}
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].at.Before(timed[j].at) })
+
+	var sessions []WorkSession
+	for i, tc := range timed {
+		if i == 0 || tc.at.Sub(timed[i-1].at) > opts.IdleGap {
+			sessions = append(sessions, WorkSession{Start: tc.at.Add(-opts.LeadIn), End: tc.at})
+		}
+		cur := &sessions[len(sessions)-1]
+		cur.End = tc.at
+		cur.Commits = append(cur.Commits, tc.hash)
+	}
+	return sessions
+}
+
+// SessionMinutes attributes session time to commits: each commit gets the
+// time since the previous commit in its session, and the first commit gets
+// the lead-in.
+func SessionMinutes(commits []Commit, opts SessionOptions) map[string]int {
+	byHash := make(map[string]time.Time, len(commits))
+	for _, c := range commits {
+		byHash[c.Hash] = c.AuthoredAt
+	}
+	out := make(map[string]int)
+	for _, s := range BuildSessions(commits, opts) {
+		prev := s.Start
+		for _, hash := range s.Commits {
+			at := byHash[hash]
+			out[hash] += int(math.Round(at.Sub(prev).Minutes()))
+			prev = at
+		}
+	}
+	return out
+}
+
+// EstimateHours sums the session minutes of a task's commits and rounds to
+// whole hours, with a minimum of one. ok is false when none of the commits
+// have session time.
+func EstimateHours(commits []string, minutes map[string]int) (hours int, ok bool) {
+	total := 0
+	for _, hash := range commits {
+		if m, found := minutes[shortHash(hash)]; found {
+			total += m
+			ok = true
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	hours = int(math.Round(float64(total) / 60))
+	if hours < 1 {
+		hours = 1
+	}
+	return hours, true
+}
+
+// ApplySessionEstimates sets EstimatedHours on commit-based tasks from
+// session time. With override false, only tasks without an estimate are
+// filled in.
+func ApplySessionEstimates(tasks []TaskChange, minutes map[string]int, override bool) []TaskChange {
+	for i := range tasks {
+		if tasks[i].IsManual {
+			continue
+		}
+		if !override && tasks[i].EstimatedHours != nil && *tasks[i].EstimatedHours > 0 {
+			continue
+		}
+		if hours, ok := EstimateHours(tasks[i].Commits, minutes); ok {
+			tasks[i].EstimatedHours = &hours
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,58 @@
+package gitdiff
+
+import (
+	"testing"
+	"time"
+)
+
+func at(clock string) time.Time {
+	t, _ := time.Parse("2006-01-02 15:04", "2026-02-05 "+clock)
+	return t
+}
+
+func TestSessionMinutesSplitsOnIdleGap(t *testing.T) {
+	commits := []Commit{
+		{Hash: "c3", AuthoredAt: at("14:00")},
+		{Hash: "c1", AuthoredAt: at("09:00")},
+		{Hash: "c2", AuthoredAt: at("10:30")},
+		{Hash: "c4", AuthoredAt: at("14:45")},
+		{Hash: "legacy"},
+	}
+	opts := SessionOptions{IdleGap: 2 * time.Hour, LeadIn: 30 * time.Minute}
+
+	sessions := BuildSessions(commits, opts)
+	if len(sessions) != 2 || len(sessions[0].Commits) != 2 || sessions[1].Duration() != 75*time.Minute {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+
+	minutes := SessionMinutes(commits, opts)
+	want := map[string]int{"c1": 30, "c2": 90, "c3": 30, "c4": 45}
+	for hash, m := range want {
+		if minutes[hash] != m {
+			t.Fatalf("expected %s=%d, got %v", hash, m, minutes)
+		}
+	}
+	if _, ok := minutes["legacy"]; ok {
+		t.Fatal("commits without timestamps should not get session time")
+	}
+}
+
+func TestApplySessionEstimates(t *testing.T) {
+	llmGuess := 6
+	tasks := []TaskChange{
+		{TaskIntent: "a", Commits: []string{"c1", "c2"}},
+		{TaskIntent: "b", Commits: []string{"c3"}, EstimatedHours: &llmGuess},
+		{TaskIntent: "manual", IsManual: true},
+	}
+	minutes := map[string]int{"c1": 30, "c2": 90, "c3": 20}
+
+	prior := ApplySessionEstimates(append([]TaskChange{}, tasks...), minutes, false)
+	if *prior[0].EstimatedHours != 2 || *prior[1].EstimatedHours != 6 || prior[2].EstimatedHours != nil {
+		t.Fatalf("unexpected prior estimates: %+v", prior)
+	}
+
+	direct := ApplySessionEstimates(append([]TaskChange{}, tasks...), minutes, true)
+	if *direct[1].EstimatedHours != 1 {
+		t.Fatalf("expected direct mode to replace the LLM estimate, got %d", *direct[1].EstimatedHours)
+	}
+}
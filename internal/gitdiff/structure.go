@@ -0,0 +1,71 @@
+package gitdiff
+
+import (
+	"strings"
+
+	"md2slack/internal/goast"
+	"md2slack/internal/hintdetector"
+	"md2slack/internal/outline"
+)
+
+// codeChange is a declaration-level signal from parsing a changed file.
+type codeChange struct {
+	kind SignalType
+	hint string
+}
+
+// ExtractSignalsAt extracts the signals of a file changed by a commit. Go,
+// TypeScript, JavaScript and Python files are also compared before and
+// after the commit, from the old path of a rename or copy, for the
+// functions, types, components and routes they change. Those signals
+// replace the generic flow-control and route hints. Files that cannot be
+// read or parsed get the line detectors only. The detectors are those of
+// the repository, see DetectorsFor.
+func ExtractSignalsAt(repoPath string, rev string, file DiffFile) Signal {
+	return extractSignalsAt(repoPath, rev, file, DetectorsFor(repoPath))
+}
+
+func extractSignalsAt(repoPath string, rev string, file DiffFile, specs []hintdetector.Spec) Signal {
+	isGo := strings.HasSuffix(file.Path, ".go")
+	if !isGo && !outline.Supports(file.Path) {
+		return extractSignals(file, specs, nil, false)
+	}
+	var before, after string
+	var err error
+	if !file.IsNew {
+		oldPath := file.Path
+		if file.OldPath != "" {
+			oldPath = file.OldPath
+		}
+		if before, err = CurrentBackend().FileAt(repoPath, rev+"^", oldPath); err != nil {
+			return extractSignals(file, specs, nil, false)
+		}
+	}
+	if !file.IsDeleted {
+		if after, err = CurrentBackend().FileAt(repoPath, rev, file.Path); err != nil {
+			return extractSignals(file, specs, nil, false)
+		}
+	}
+
+	var code []codeChange
+	if isGo {
+		changes, err := goast.Compare(before, after)
+		if err != nil {
+			return extractSignals(file, specs, nil, false)
+		}
+		for _, c := range changes {
+			code = append(code, codeChange{SignalType(c.Kind), c.Hint})
+		}
+	} else {
+		for _, c := range outline.Compare(file.Path, before, after) {
+			code = append(code, codeChange{SignalType(c.Kind), c.Hint})
+		}
+	}
+	return extractSignals(file, specs, code, true)
+}
+
+// replacedByStructure reports whether a line detector's hints are covered
+// by the declaration-level signals of a parsed file.
+func replacedByStructure(name string) bool {
+	return name == "logic" || name == "express"
+}
@@ -0,0 +1,155 @@
+package gitdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"md2slack/internal/hintdetector"
+)
+
+func TestExtractSignalsAtParsesGoFiles(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{
+		"cart.go": "package cart\n\nfunc Total() int {\n\treturn 0\n}\n",
+	})
+	writeAndCommit(t, dir, "Ana", "2026-02-05T10:00:00", map[string]string{
+		"cart.go":   "package cart\n\nfunc Total() int {\n\tif len(items) == 0 {\n\t\treturn 0\n\t}\n\treturn 1\n}\n\nvar items []int\n",
+		"broken.go": "package cart\n\nfunc broken( {\n\treturn\n}\n",
+	})
+	rev, err := Git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cart := DiffFile{Path: "cart.go", Additions: []string{"\tif len(items) == 0 {", "\t\treturn 0", "\t}", "\treturn 1"}}
+	broken := DiffFile{Path: "broken.go", IsNew: true, Additions: []string{"func broken( {", "\treturn"}}
+
+	for _, b := range []Backend{ExecBackend{}, GoGitBackend{}} {
+		SetBackend(b)
+		s := ExtractSignalsAt(dir, rev, cart)
+		if !reflect.DeepEqual(s.Types, []SignalType{SignalFuncChanged}) || !reflect.DeepEqual(s.Hints, []string{"changed func Total"}) {
+			t.Fatalf("%s: unexpected Go signal: %+v", b.Name(), s)
+		}
+		// Files that do not parse fall back to the line detectors
+		if s := ExtractSignalsAt(dir, rev, broken); !containsType(s.Types, SignalLogicChange) {
+			t.Fatalf("%s: expected line-based signals for an unparsable file, got %+v", b.Name(), s)
+		}
+	}
+	SetBackend(ExecBackend{})
+
+	if s := ExtractSignals(cart); !containsType(s.Types, SignalLogicChange) || strings.Contains(strings.Join(s.Hints, ","), "changed func") {
+		t.Fatalf("unexpected line-only signal: %+v", s)
+	}
+}
+
+func TestExtractSignalsAtOutlinesScripts(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{
+		"routes/orders.js": "router.post(\"/orders\", (req, res) => {\n  res.end();\n});\n",
+	})
+	writeAndCommit(t, dir, "Ana", "2026-02-05T10:00:00", map[string]string{
+		"routes/orders.js": "router.post(\"/orders\", (req, res) => {\n  if (!req.body) return res.status(400).end();\n  res.end();\n});\n",
+	})
+	rev, err := Git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := ExtractSignalsAt(dir, rev, DiffFile{Path: "routes/orders.js", Additions: []string{"  if (!req.body) return res.status(400).end();"}})
+	if !reflect.DeepEqual(s.Types, []SignalType{SignalRouteChange}) || !reflect.DeepEqual(s.Hints, []string{"changed handler POST /orders"}) {
+		t.Fatalf("unexpected script signal: %+v", s)
+	}
+}
+
+func containsType(types []SignalType, want SignalType) bool {
+	for _, t := range types {
+		if t == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExtractSignalsLocations(t *testing.T) {
+	patch := "diff --git a/wait.spec.ts b/wait.spec.ts\n--- a/wait.spec.ts\n+++ b/wait.spec.ts\n" +
+		"@@ -7,2 +7,3 @@ describe(\"checkout\", () => {\n" +
+		"   it(\"pays\", async () => {\n" +
+		"+    await waitFor(done, { timeout: 5000 })\n" +
+		"     expect(paid).toBe(true)\n" +
+		"@@ -20,2 +21,2 @@ describe(\"refunds\", () => {\n" +
+		"-    setTimeout(next, 100)\n" +
+		"+    setTimeout(next, 500) // timeout for slow CI\n" +
+		"     expect(refunded).toBe(true)\n"
+	files := parseFiles(strings.Split(patch, "\n"))
+	var got []Location
+	for _, loc := range ExtractSignals(files[0]).Locations {
+		if loc.Type == SignalTimeoutChange {
+			got = append(got, loc)
+		}
+	}
+	want := []Location{
+		{Type: SignalTimeoutChange, Line: 8, Section: `describe("checkout", () => {`, Edit: EditAdded},
+		{Type: SignalTimeoutChange, Line: 21, Section: `describe("refunds", () => {`, Edit: EditModified},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected locations: %+v", got)
+	}
+}
+
+func TestExtractSignalsRenames(t *testing.T) {
+	moved := ExtractSignals(DiffFile{Path: "billing/pay.go", OldPath: "pay.go", IsRename: true, Similarity: 100})
+	if !reflect.DeepEqual(moved.Types, []SignalType{SignalRename}) || !reflect.DeepEqual(moved.Hints, []string{"moved pay.go to billing/pay.go"}) {
+		t.Fatalf("unexpected rename signal: %+v", moved)
+	}
+	copied := ExtractSignals(DiffFile{Path: "b.go", OldPath: "a.go", IsCopy: true, Similarity: 100})
+	if !reflect.DeepEqual(copied.Types, []SignalType{SignalNewFile}) || !reflect.DeepEqual(copied.Hints, []string{"copied b.go from a.go"}) {
+		t.Fatalf("unexpected copy signal: %+v", copied)
+	}
+}
+
+func TestExtractSignalsAtReadsRenamedFiles(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{
+		"pay.go": "package pay\n\nfunc Charge() {}\n\nfunc Refund() {}\n\nfunc Void() {}\n",
+	})
+	if _, err := Git(dir, "mv", "pay.go", "billing.go"); err != nil {
+		t.Fatal(err)
+	}
+	writeAndCommit(t, dir, "Ana", "2026-02-05T10:00:00", map[string]string{
+		"billing.go": "package pay\n\nfunc Charge() {}\n\nfunc Refund() {}\n\nfunc Void() {}\n\nfunc Capture() {}\n",
+	})
+	for _, b := range []Backend{ExecBackend{}, GoGitBackend{}} {
+		SetBackend(b)
+		commits, err := b.Commits(dir, CommitQuery{})
+		if err != nil || len(commits) != 2 || len(commits[0].Files) != 1 {
+			t.Fatalf("%s: unexpected commits %+v %v", b.Name(), commits, err)
+		}
+		s := ExtractSignalsAt(dir, commits[0].FullHash, commits[0].Files[0])
+		if !reflect.DeepEqual(s.Types, []SignalType{SignalRename, SignalFuncAdded, SignalAPIChange}) {
+			t.Fatalf("%s: unexpected signal: %+v", b.Name(), s)
+		}
+	}
+	SetBackend(ExecBackend{})
+}
+
+func TestExtractSignalsRunsUserDetectors(t *testing.T) {
+	d, err := hintdetector.NewRuleDetector(hintdetector.Rule{
+		Name: "rails-callback", Languages: []string{"ruby"},
+		Pattern: `before_action :(\w+)`, Signal: "auth_change", Hint: "added ${1} callback",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetUserDetectors([]hintdetector.Spec{d.Spec()})
+	defer SetUserDetectors(nil)
+
+	s := ExtractSignals(DiffFile{Path: "app/controllers/orders_controller.rb", Additions: []string{"  before_action :authenticate_user!"}})
+	if !reflect.DeepEqual(s.Types, []SignalType{"auth_change"}) || !reflect.DeepEqual(s.Hints, []string{"added authenticate_user callback"}) {
+		t.Fatalf("unexpected signal: %+v", s)
+	}
+	if s.Weights["auth_change"] != 0.5 {
+		t.Fatalf("expected the default rule weight, got %v", s.Weights)
+	}
+	if s := ExtractSignals(DiffFile{Path: "orders.py", Additions: []string{"before_action :authenticate_user!"}}); len(s.Types) != 0 {
+		t.Fatalf("expected the ruby rule to skip python files, got %+v", s)
+	}
+}
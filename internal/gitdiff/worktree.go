@@ -0,0 +1,150 @@
+package gitdiff
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"md2slack/internal/hintdetector"
+)
+
+// WorkingTree is uncommitted work: staged and unstaged changes against HEAD,
+// untracked files, and stashes.
+type WorkingTree struct {
+	Files   []DiffFile // changes against HEAD; untracked files are new files without content
+	Stashes []Stash    // newest first
+}
+
+// Stash is one stash entry and the changes it holds.
+type Stash struct {
+	Ref     string // e.g. "stash@{0}"
+	Message string
+	At      time.Time
+	Files   []DiffFile
+}
+
+// WorkInProgress is one unit of uncommitted work, ready for the same signal
+// extraction and intent stages as a commit.
+type WorkInProgress struct {
+	Ref      string // "working-tree" or a stash ref
+	Message  string
+	Files    []DiffFile
+	Semantic CommitSemantic
+	Secrets  []SecretFinding // masked in Message and Files
+}
+
+const WorkingTreeRef = "working-tree"
+
+// GetWorkInProgress returns the working tree changes and the stashes created
+// since the given time, each with its extracted signals. Entries without
+// changes are left out.
+func GetWorkInProgress(repoPath string, stashesSince time.Time) ([]WorkInProgress, error) {
+	wt, err := CurrentBackend().WorkingTree(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := NewDiffFilter(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	specs := DetectorsFor(repoPath)
+	var out []WorkInProgress
+	if files := filter.Files(wt.Files); len(files) > 0 {
+		out = append(out, newWorkInProgress(WorkingTreeRef, "Uncommitted changes in the working tree", files, specs))
+	}
+	for _, s := range wt.Stashes {
+		if s.At.Before(stashesSince) {
+			continue
+		}
+		if files := filter.Files(s.Files); len(files) > 0 {
+			out = append(out, newWorkInProgress(s.Ref, s.Message, files, specs))
+		}
+	}
+	return out, nil
+}
+
+func newWorkInProgress(ref string, message string, files []DiffFile, specs []hintdetector.Spec) WorkInProgress {
+	w := WorkInProgress{Ref: ref, Files: files}
+	w.Message, _ = RedactSecrets(message)
+	w.Secrets = redactFiles(ref, files)
+	w.Semantic = CommitSemantic{CommitHash: ref, FilesTouched: len(files)}
+	signals := make([]Signal, len(files))
+	for i, f := range files {
+		if f.IsTest {
+			w.Semantic.TouchesTests = true
+		}
+		signals[i] = extractSignals(f, specs, nil, false)
+	}
+	ApplyTestCoverage(files, signals)
+	for _, s := range signals {
+		if len(s.Types) > 0 || len(s.Hints) > 0 {
+			w.Semantic.Signals = append(w.Semantic.Signals, s)
+		}
+	}
+	return w
+}
+
+// WorkInProgressTask turns uncommitted work into an in-progress task. cc is
+// the intent extracted for it; when nil, the task is described from the
+// changed files. Hints that quote the changed code are kept only withCode.
+func WorkInProgressTask(w WorkInProgress, cc *CommitChange, withCode bool) TaskChange {
+	t := TaskChange{
+		TaskType: "feature",
+		Status:   "in_progress",
+	}
+	if cc != nil && strings.TrimSpace(cc.Intent) != "" {
+		t.TaskIntent = cc.Intent
+		t.Scope = cc.Scope
+		if cc.ChangeType != "" {
+			t.TaskType = cc.ChangeType
+		}
+		t.Confidence = cc.Confidence
+	} else {
+		t.TaskIntent = "work in progress on " + summarizePaths(w.Files, 3)
+	}
+
+	var details []string
+	if w.Ref == WorkingTreeRef {
+		details = append(details, "Uncommitted changes: "+summarizePaths(w.Files, 5))
+	} else {
+		details = append(details, "Stashed ("+w.Ref+"): "+summarizePaths(w.Files, 5))
+	}
+	for _, s := range w.Semantic.Signals {
+		for _, h := range s.Hints {
+			if withCode || !slices.Contains(s.CodeHints, h) {
+				details = append(details, h)
+			}
+		}
+	}
+	t.TechnicalWhy = strings.Join(details, "\n")
+	return t
+}
+
+func summarizePaths(files []DiffFile, limit int) string {
+	var paths []string
+	for i, f := range files {
+		if i == limit {
+			paths = append(paths, "and "+strconv.Itoa(len(files)-limit)+" more")
+			break
+		}
+		paths = append(paths, f.Path)
+	}
+	return strings.Join(paths, ", ")
+}
+
+// AppendWorkInProgressTasks replaces the in-progress tasks from an earlier
+// run with the current ones, so re-running a day does not stack up copies.
+func AppendWorkInProgressTasks(tasks []TaskChange, wip []TaskChange) []TaskChange {
+	var out []TaskChange
+	for _, t := range tasks {
+		if !t.IsWorkInProgress {
+			out = append(out, t)
+		}
+	}
+	for _, t := range wip {
+		t.IsWorkInProgress = true
+		out = append(out, t)
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package gitdiff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackendsAgreeOnWorkingTree(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{
+		"main.go":   "package main\n\nfunc main() {}\n",
+		"README.md": "hello\n",
+	})
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n\nfunc main() {\n\trun()\n}\n")
+	write("handler.go", "package main\n\nfunc run() {}\n")
+	if _, err := Git(dir, "add", "handler.go"); err != nil {
+		t.Fatal(err)
+	}
+	write("notes.txt", "todo\n")
+	if err := os.Remove(filepath.Join(dir, "README.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	type summary struct {
+		Path         string
+		New, Deleted bool
+		HasAdditions bool
+		HasDeletions bool
+	}
+	summarize := func(files []DiffFile) []summary {
+		var out []summary
+		for _, f := range files {
+			out = append(out, summary{f.Path, f.IsNew, f.IsDeleted, len(f.Additions) > 0, len(f.Deletions) > 0})
+		}
+		return out
+	}
+	want := []summary{
+		{"README.md", false, true, false, true},
+		{"handler.go", true, false, true, false},
+		{"main.go", false, false, true, true},
+		{"notes.txt", true, false, false, false},
+	}
+	for _, b := range []Backend{ExecBackend{}, GoGitBackend{}} {
+		wt, err := b.WorkingTree(dir)
+		if err != nil {
+			t.Fatalf("%s: %v", b.Name(), err)
+		}
+		if got := summarize(wt.Files); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: unexpected files %+v", b.Name(), got)
+		}
+	}
+}
+
+func TestExecBackendStashes(t *testing.T) {
+	dir := initTestRepo(t)
+	writeAndCommit(t, dir, "Ana", "2026-02-04T09:00:00", map[string]string{"cart.go": "package cart\n"})
+	if err := os.WriteFile(filepath.Join(dir, "cart.go"), []byte("package cart\n\nfunc Total() int { return 0 }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Git(dir, "-c", "user.name=Ana", "-c", "user.email=ana@example.com", "stash", "push", "-q", "-m", "cart totals"); err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := ExecBackend{}.WorkingTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wt.Files) != 0 {
+		t.Fatalf("expected a clean working tree, got %+v", wt.Files)
+	}
+	if len(wt.Stashes) != 1 {
+		t.Fatalf("expected one stash, got %+v", wt.Stashes)
+	}
+	s := wt.Stashes[0]
+	if s.Ref != "stash@{0}" || !strings.Contains(s.Message, "cart totals") || s.At.IsZero() {
+		t.Fatalf("unexpected stash: %+v", s)
+	}
+	if len(s.Files) != 1 || s.Files[0].Path != "cart.go" || len(s.Files[0].Additions) == 0 {
+		t.Fatalf("unexpected stash files: %+v", s.Files)
+	}
+
+	wip, err := GetWorkInProgress(dir, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wip) != 0 {
+		t.Fatalf("expected stashes before the cutoff to be left out, got %+v", wip)
+	}
+}
+
+func TestWorkInProgressTasks(t *testing.T) {
+	w := newWorkInProgress(WorkingTreeRef, "Uncommitted changes in the working tree", []DiffFile{
+		{Path: "cart.go", Additions: []string{"func Total() int { return 0 }"}},
+		{Path: "cart_test.go", IsNew: true, IsTest: true},
+	}, globalDetectors())
+	if !w.Semantic.TouchesTests || w.Semantic.FilesTouched != 2 {
+		t.Fatalf("unexpected semantic: %+v", w.Semantic)
+	}
+
+	task := WorkInProgressTask(w, nil, true)
+	if task.Status != "in_progress" || task.TaskIntent != "work in progress on cart.go, cart_test.go" {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+	if !strings.HasPrefix(task.TechnicalWhy, "Uncommitted changes: cart.go, cart_test.go") {
+		t.Fatalf("unexpected technical why: %q", task.TechnicalWhy)
+	}
+	quoted := WorkInProgress{Ref: WorkingTreeRef, Files: w.Files, Semantic: CommitSemantic{Signals: []Signal{{
+		File:      "cart.go",
+		Hints:     []string{"timeout adjusted", "calls stripe.charges.create(total)"},
+		CodeHints: []string{"calls stripe.charges.create(total)"},
+	}}}}
+	if why := WorkInProgressTask(quoted, nil, false).TechnicalWhy; strings.Contains(why, "stripe") || !strings.Contains(why, "timeout adjusted") {
+		t.Fatalf("expected code-quoting hints dropped without code: %q", why)
+	}
+	if why := WorkInProgressTask(quoted, nil, true).TechnicalWhy; !strings.Contains(why, "stripe") {
+		t.Fatalf("expected code-quoting hints with code: %q", why)
+	}
+	named := WorkInProgressTask(w, &CommitChange{Intent: "add cart totals", ChangeType: "feature", Scope: "cart"}, true)
+	if named.TaskIntent != "add cart totals" || named.Scope != "cart" {
+		t.Fatalf("unexpected named task: %+v", named)
+	}
+
+	tasks := []TaskChange{
+		{TaskIntent: "ship checkout", Status: "done"},
+		{TaskIntent: "stale draft", Status: "in_progress", IsWorkInProgress: true},
+	}
+	got := AppendWorkInProgressTasks(tasks, []TaskChange{named})
+	if len(got) != 2 || got[0].TaskIntent != "ship checkout" || got[1].TaskIntent != "add cart totals" || !got[1].IsWorkInProgress {
+		t.Fatalf("unexpected tasks: %+v", got)
+	}
+}
@@ -0,0 +1,325 @@
+// Package goast compares two versions of a Go source file and reports the
+// declarations that changed: functions, exported API, types, struct fields
+// and error handling.
+package goast
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// Change kinds. They double as signal types in gitdiff.
+const (
+	FuncAdded   = "func_added"
+	FuncRemoved = "func_removed"
+	FuncChanged = "func_changed"
+	APIChange   = "api_change"
+	TypeAdded   = "type_added"
+	FieldChange = "field_change"
+	ErrorPath   = "error_handling"
+)
+
+// Change is one declaration-level difference between two versions.
+type Change struct {
+	Kind string
+	Name string // function ("Type.Method" for methods) or type name
+	Hint string
+}
+
+// Compare parses before and after and returns their differences, functions
+// first and then types, each sorted by name. An empty before is a new file
+// and an empty after a deleted one. A version that does not parse is an
+// error, so callers can fall back to line-based hints.
+func Compare(before, after string) ([]Change, error) {
+	old, err := parse("before", before)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := parse("after", after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	add := func(kind, name, format string, args ...interface{}) {
+		changes = append(changes, Change{Kind: kind, Name: name, Hint: fmt.Sprintf(format, args...)})
+	}
+
+	for _, name := range sortedKeys(cur.funcs, old.funcs) {
+		o, inOld := old.funcs[name]
+		c, inCur := cur.funcs[name]
+		switch {
+		case !inOld:
+			add(FuncAdded, name, "added func %s", name)
+			if c.exported {
+				add(APIChange, name, "exported API: added %s", name)
+			}
+		case !inCur:
+			add(FuncRemoved, name, "removed func %s", name)
+			if o.exported {
+				add(APIChange, name, "exported API: removed %s", name)
+			}
+		default:
+			if o.signature != c.signature {
+				add(FuncChanged, name, "changed signature of %s", name)
+				if c.exported {
+					add(APIChange, name, "exported API: changed signature of %s", name)
+				}
+			} else if o.body != c.body {
+				add(FuncChanged, name, "changed func %s", name)
+			}
+			if o.errorPaths != c.errorPaths {
+				add(ErrorPath, name, "changed error handling in %s (%d -> %d error paths)", name, o.errorPaths, c.errorPaths)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(cur.types, old.types) {
+		o, inOld := old.types[name]
+		c, inCur := cur.types[name]
+		switch {
+		case !inOld:
+			add(TypeAdded, name, "added type %s", name)
+			if c.exported {
+				add(APIChange, name, "exported API: added type %s", name)
+			}
+		case !inCur:
+			if o.exported {
+				add(APIChange, name, "exported API: removed type %s", name)
+			}
+		case o.fields != nil && c.fields != nil:
+			if diff := fieldDiff(o.fields, c.fields); diff != "" {
+				add(FieldChange, name, "struct %s: %s", name, diff)
+				if c.exported {
+					add(APIChange, name, "exported API: changed fields of %s", name)
+				}
+			}
+		case o.definition != c.definition:
+			add(FieldChange, name, "changed type %s", name)
+		}
+	}
+	return changes, nil
+}
+
+type funcInfo struct {
+	exported   bool
+	signature  string
+	body       string
+	errorPaths int
+}
+
+type typeInfo struct {
+	exported   bool
+	definition string
+	fields     map[string]string // struct fields and their types; nil for other types
+}
+
+type fileInfo struct {
+	funcs map[string]funcInfo
+	types map[string]typeInfo
+}
+
+func parse(name string, src string) (*fileInfo, error) {
+	info := &fileInfo{funcs: map[string]funcInfo{}, types: map[string]typeInfo{}}
+	if strings.TrimSpace(src) == "" {
+		return info, nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name+".go", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			fn := funcInfo{
+				exported:  d.Name.IsExported() && (d.Recv == nil || ast.IsExported(receiverType(d))),
+				signature: render(fset, d.Type),
+			}
+			if d.Recv != nil {
+				fn.signature = render(fset, d.Recv) + fn.signature
+			}
+			if d.Body != nil {
+				fn.body = render(fset, d.Body)
+				fn.errorPaths = countErrorPaths(d.Body)
+			}
+			info.funcs[funcName(d)] = fn
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				t := typeInfo{exported: ts.Name.IsExported(), definition: render(fset, ts.Type)}
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					t.fields = structFields(fset, st)
+				}
+				info.types[ts.Name.Name] = t
+			}
+		}
+	}
+	return info, nil
+}
+
+func funcName(d *ast.FuncDecl) string {
+	if d.Recv == nil {
+		return d.Name.Name
+	}
+	return receiverType(d) + "." + d.Name.Name
+}
+
+// receiverType returns the receiver's type name without pointer or type
+// parameters.
+func receiverType(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return ""
+	}
+	expr := d.Recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func structFields(fset *token.FileSet, st *ast.StructType) map[string]string {
+	fields := make(map[string]string)
+	for _, field := range st.Fields.List {
+		typ := render(fset, field.Type)
+		if field.Tag != nil {
+			typ += " " + field.Tag.Value
+		}
+		if len(field.Names) == 0 {
+			fields[render(fset, field.Type)] = typ // embedded
+		}
+		for _, n := range field.Names {
+			fields[n.Name] = typ
+		}
+	}
+	return fields
+}
+
+func fieldDiff(old, cur map[string]string) string {
+	var added, removed, changed []string
+	for _, name := range sortedKeys(cur, old) {
+		o, inOld := old[name]
+		c, inCur := cur[name]
+		switch {
+		case !inOld:
+			added = append(added, name)
+		case !inCur:
+			removed = append(removed, name)
+		case o != c:
+			changed = append(changed, name)
+		}
+	}
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, "added "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(removed, ", "))
+	}
+	if len(changed) > 0 {
+		parts = append(parts, "changed "+strings.Join(changed, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// countErrorPaths counts the places a function body handles or produces an
+// error: "if err != nil" checks, "return ..., err", and errors.New,
+// fmt.Errorf and panic calls.
+func countErrorPaths(body *ast.BlockStmt) int {
+	n := 0
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.IfStmt:
+			if isErrCheck(x.Cond) {
+				n++
+			}
+		case *ast.ReturnStmt:
+			if len(x.Results) > 0 && isErrIdent(x.Results[len(x.Results)-1]) {
+				n++
+			}
+		case *ast.CallExpr:
+			switch callName(x) {
+			case "errors.New", "fmt.Errorf", "panic":
+				n++
+			}
+		}
+		return true
+	})
+	return n
+}
+
+func isErrCheck(cond ast.Expr) bool {
+	b, ok := cond.(*ast.BinaryExpr)
+	if !ok {
+		return false
+	}
+	if b.Op == token.LAND || b.Op == token.LOR {
+		return isErrCheck(b.X) || isErrCheck(b.Y)
+	}
+	if b.Op != token.NEQ && b.Op != token.EQL {
+		return false
+	}
+	return (isErrIdent(b.X) && isNil(b.Y)) || (isNil(b.X) && isErrIdent(b.Y))
+}
+
+func isErrIdent(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && (id.Name == "err" || strings.HasSuffix(id.Name, "Err"))
+}
+
+func isNil(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "nil"
+}
+
+func callName(call *ast.CallExpr) string {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := fn.X.(*ast.Ident); ok {
+			return pkg.Name + "." + fn.Sel.Name
+		}
+	}
+	return ""
+}
+
+func render(fset *token.FileSet, node interface{}) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, node)
+	return buf.String()
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
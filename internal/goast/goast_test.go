@@ -0,0 +1,116 @@
+package goast
+
+import (
+	"reflect"
+	"testing"
+)
+
+const before = `package cart
+
+import "errors"
+
+type Cart struct {
+	Items []Item
+	Owner string
+}
+
+type Item struct{ SKU string }
+
+func (c *Cart) Total() int {
+	return 0
+}
+
+func (c *Cart) Add(it Item) error {
+	if it.SKU == "" {
+		return errors.New("missing sku")
+	}
+	c.Items = append(c.Items, it)
+	return nil
+}
+
+func legacy() {}
+`
+
+const after = `package cart
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Cart struct {
+	Items    []Item
+	Owner    int
+	Discount float64
+}
+
+type Item struct{ SKU string }
+
+type Coupon struct{ Code string }
+
+// Total now applies the discount.
+func (c *Cart) Total() int {
+	return int(float64(len(c.Items)) * (1 - c.Discount))
+}
+
+func (c *Cart) Add(it Item) error {
+	if it.SKU == "" {
+		return errors.New("missing sku")
+	}
+	if err := validate(it); err != nil {
+		return fmt.Errorf("add %s: %w", it.SKU, err)
+	}
+	c.Items = append(c.Items, it)
+	return nil
+}
+
+func (c *Cart) Apply(code string, pct float64) {}
+
+func validate(it Item) error { return nil }
+`
+
+func TestCompare(t *testing.T) {
+	changes, err := Compare(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hints []string
+	for _, c := range changes {
+		hints = append(hints, c.Hint)
+	}
+	want := []string{
+		"changed func Cart.Add",
+		"changed error handling in Cart.Add (1 -> 3 error paths)",
+		"added func Cart.Apply",
+		"exported API: added Cart.Apply",
+		"changed func Cart.Total",
+		"removed func legacy",
+		"added func validate",
+		"struct Cart: added Discount; changed Owner",
+		"exported API: changed fields of Cart",
+		"added type Coupon",
+		"exported API: added type Coupon",
+	}
+	if !reflect.DeepEqual(hints, want) {
+		t.Fatalf("unexpected changes:\n%q", hints)
+	}
+}
+
+func TestCompareNewFileAndParseErrors(t *testing.T) {
+	changes, err := Compare("", "package x\n\nfunc run() {}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Kind != FuncAdded || changes[0].Name != "run" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+
+	if _, err := Compare("package x\n", "package x\n\nfunc broken( {\n"); err == nil {
+		t.Fatal("expected a parse error")
+	}
+
+	unchanged, err := Compare("package x\n\nfunc run() {}\n", "package x\n\n// run does nothing.\nfunc run() {\n}\n")
+	if err != nil || len(unchanged) != 0 {
+		t.Fatalf("expected comment and formatting changes to be ignored, got %+v %v", unchanged, err)
+	}
+}
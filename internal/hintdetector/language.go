@@ -0,0 +1,43 @@
+package hintdetector
+
+import (
+	"path"
+	"strings"
+)
+
+var languagesByExt = map[string]string{
+	".go":     "go",
+	".js":     "javascript",
+	".jsx":    "javascript",
+	".mjs":    "javascript",
+	".cjs":    "javascript",
+	".ts":     "typescript",
+	".tsx":    "typescript",
+	".mts":    "typescript",
+	".cts":    "typescript",
+	".py":     "python",
+	".rb":     "ruby",
+	".erb":    "ruby",
+	".java":   "java",
+	".kt":     "kotlin",
+	".kts":    "kotlin",
+	".scala":  "scala",
+	".php":    "php",
+	".rs":     "rust",
+	".cs":     "csharp",
+	".swift":  "swift",
+	".ex":     "elixir",
+	".exs":    "elixir",
+	".sql":    "sql",
+	".css":    "css",
+	".scss":   "css",
+	".html":   "html",
+	".vue":    "vue",
+	".svelte": "svelte",
+}
+
+// Language returns the language of a file from its extension, e.g. "ruby"
+// for app/models/user.rb, or "" when unknown.
+func Language(file string) string {
+	return languagesByExt[strings.ToLower(path.Ext(file))]
+}
@@ -0,0 +1,85 @@
+package hintdetector
+
+import "slices"
+
+// Spec registers a detector under a name, with the file languages and repo
+// frameworks it is meant for and the confidence of its signals.
+type Spec struct {
+	Name     string
+	Detector Detector
+	// Languages limit the detector to files of these languages, see
+	// Language. It runs on every file when empty.
+	Languages []string
+	// Frameworks the repo must use for the detector to be auto-enabled,
+	// see DetectStack. Detectors without frameworks are always enabled.
+	Frameworks []string
+	// Weight is how far its signals can be trusted, from 0 to 1.
+	Weight float64
+	// QuotesCode is set when hints copy text from the changed lines.
+	QuotesCode bool
+}
+
+// AppliesTo reports whether the detector runs on a file.
+func (s Spec) AppliesTo(path string) bool {
+	return len(s.Languages) == 0 || slices.Contains(s.Languages, Language(path))
+}
+
+var scriptLanguages = []string{"javascript", "typescript"}
+
+// Builtins returns the built-in detectors in the order they run.
+func Builtins() []Spec {
+	return []Spec{
+		{Name: "timeout", Detector: TimeoutDetector{}, Languages: scriptLanguages, Weight: 0.6},
+		{Name: "error_handling", Detector: ErrorHandlingDetector{}, Weight: 0.5},
+		{Name: "schema", Detector: SchemaDetector{}, Weight: 0.6},
+		{Name: "ui_state", Detector: UIDetector{}, Languages: scriptLanguages, Frameworks: []string{"react"}, Weight: 0.4},
+		{Name: "logic", Detector: LogicDetector{}, Weight: 0.2},
+		{Name: "nextjs", Detector: NextJSDetector{}, Languages: scriptLanguages, Frameworks: []string{"nextjs"}, Weight: 0.7},
+		{Name: "express", Detector: ExpressDetector{}, Languages: scriptLanguages, Frameworks: []string{"express"}, Weight: 0.6},
+		{Name: "drizzle", Detector: DrizzleDetector{}, Languages: scriptLanguages, Frameworks: []string{"drizzle"}, Weight: 0.7},
+		{Name: "migration", Detector: MigrationDetector{}, Weight: 0.7},
+		{Name: "typescript", Detector: TypeScriptDetector{}, Languages: []string{"typescript"}, Weight: 0.4},
+		{Name: "auth", Detector: AuthDetector{}, Weight: 0.6},
+		{Name: "refactor", Detector: RefactorDetector{}, Weight: 0.3},
+		{Name: "test_stability", Detector: TestStabilityDetector{}, Weight: 0.5},
+		{Name: "retry", Detector: RetryDetector{}, Weight: 0.5},
+		{Name: "state_guard", Detector: StateGuardDetector{}, Languages: scriptLanguages, Weight: 0.5},
+		{Name: "ux_bugfix", Detector: UXBugFixDetector{}, Languages: scriptLanguages, Weight: 0.3},
+		{Name: "orm_migration", Detector: ORMMigrationDetector{}, Languages: scriptLanguages, Frameworks: []string{"drizzle"}, Weight: 0.4},
+		{Name: "completion_flow", Detector: CompletionFlowDetector{}, Weight: 0.4},
+		{Name: "regression_test", Detector: RegressionTestDetector{}, Languages: scriptLanguages, Weight: 0.6},
+		{Name: "style", Detector: StyleAdjustmentDetector{}, Weight: 0.3},
+		{Name: "stability_guard", Detector: StabilityGuardDetector{}, Languages: scriptLanguages, Weight: 0.5},
+	}
+}
+
+// Selection turns detectors on and off for one repository.
+type Selection struct {
+	// Auto skips detectors for frameworks the Stack does not include.
+	Auto  bool
+	Stack Stack
+	// Disable and Enable list detector names. Disable wins; Enable keeps a
+	// detector that Auto would skip.
+	Disable []string
+	Enable  []string
+	// Weights override detector weights by name.
+	Weights map[string]float64
+}
+
+// Select returns the specs a repository runs, with weights applied.
+func Select(specs []Spec, sel Selection) []Spec {
+	var out []Spec
+	for _, s := range specs {
+		if slices.Contains(sel.Disable, s.Name) {
+			continue
+		}
+		if sel.Auto && len(s.Frameworks) > 0 && !slices.Contains(sel.Enable, s.Name) && !sel.Stack.UsesAny(s.Frameworks) {
+			continue
+		}
+		if w, ok := sel.Weights[s.Name]; ok {
+			s.Weight = w
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,86 @@
+package hintdetector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinsHaveUniqueNames(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, s := range Builtins() {
+		if s.Name == "" || seen[s.Name] || s.Weight <= 0 || s.Weight > 1 {
+			t.Errorf("bad spec %q (weight %v)", s.Name, s.Weight)
+		}
+		seen[s.Name] = true
+	}
+}
+
+func TestSelect(t *testing.T) {
+	names := func(specs []Spec) []string {
+		var out []string
+		for _, s := range specs {
+			out = append(out, s.Name)
+		}
+		return out
+	}
+	specs := []Spec{
+		{Name: "logic", Weight: 0.2},
+		{Name: "nextjs", Frameworks: []string{"nextjs"}, Weight: 0.7},
+		{Name: "drizzle", Frameworks: []string{"drizzle"}, Weight: 0.7},
+	}
+
+	tests := []struct {
+		name string
+		sel  Selection
+		want []string
+	}{
+		{"no auto", Selection{}, []string{"logic", "nextjs", "drizzle"}},
+		{"auto go repo", Selection{Auto: true, Stack: Stack{Languages: []string{"go"}}}, []string{"logic"}},
+		{"auto next repo", Selection{Auto: true, Stack: Stack{Frameworks: []string{"nextjs", "react"}}}, []string{"logic", "nextjs"}},
+		{"enable", Selection{Auto: true, Enable: []string{"drizzle"}}, []string{"logic", "drizzle"}},
+		{"disable wins", Selection{Disable: []string{"logic", "drizzle"}, Enable: []string{"drizzle"}}, []string{"nextjs"}},
+	}
+	for _, tt := range tests {
+		if got := names(Select(specs, tt.sel)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	got := Select(specs, Selection{Weights: map[string]float64{"logic": 0.9}})
+	if got[0].Weight != 0.9 || got[1].Weight != 0.7 {
+		t.Errorf("unexpected weights: %+v", got)
+	}
+	if specs[0].Weight != 0.2 {
+		t.Errorf("Select changed its input")
+	}
+}
+
+func TestDetectStack(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                        "module example.com/api\n\nrequire github.com/gin-gonic/gin v1.10.0\n",
+		"web/package.json":              `{"dependencies": {"next": "15.0.0", "react": "19.0.0"}, "devDependencies": {"typescript": "5.6.0"}}`,
+		"node_modules/x/package.json":   `{"dependencies": {"express": "4.0.0"}}`,
+		"web/node_modules/package.json": `{"dependencies": {"drizzle-orm": "1.0.0"}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := DetectStack(root)
+	want := Stack{
+		Languages:  []string{"go", "javascript", "typescript"},
+		Frameworks: []string{"gin", "nextjs", "react"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
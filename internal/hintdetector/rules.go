@@ -0,0 +1,230 @@
+package hintdetector
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is a detector defined in a YAML or TOML file instead of Go.
+type Rule struct {
+	Name string `yaml:"name" toml:"name"`
+	// Paths are globs the file must match, e.g. "db/migrate/*.rb" or
+	// "app/**/controllers/*.rb". A glob without "/" matches the file name.
+	// Any file matches when empty.
+	Paths []string `yaml:"paths" toml:"paths"`
+	// Languages limit the rule to files of these languages, see Language.
+	Languages []string `yaml:"languages" toml:"languages"`
+	// Frameworks auto-enable the rule only in repos using one of them,
+	// like the built-in detectors' Spec.Frameworks.
+	Frameworks []string `yaml:"frameworks" toml:"frameworks"`
+	// Pattern is a regular expression run on each added line.
+	Pattern string `yaml:"pattern" toml:"pattern"`
+	Signal  string `yaml:"signal" toml:"signal"`
+	// Hint may refer to capture groups as ${1} or ${name}.
+	Hint string `yaml:"hint" toml:"hint"`
+	// Weight is the confidence of the rule's signals, 0.5 when unset.
+	Weight float64 `yaml:"weight" toml:"weight"`
+}
+
+const defaultRuleWeight = 0.5
+
+// ruleFile is the layout of a detector file: a list under "detectors".
+type ruleFile struct {
+	Detectors []Rule `yaml:"detectors" toml:"detectors"`
+}
+
+// RuleDetector runs a Rule.
+type RuleDetector struct {
+	Rule
+	pattern *regexp.Regexp
+	paths   []*regexp.Regexp
+}
+
+var signalNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// captureRefRe finds capture group references in a hint, as expanded by
+// regexp.Expand; "$$" is a literal dollar.
+var captureRefRe = regexp.MustCompile(`(^|[^$])(\$\$)*\$(\{\w+\}|\w)`)
+
+// NewRuleDetector validates a rule and compiles its pattern and globs.
+func NewRuleDetector(r Rule) (*RuleDetector, error) {
+	if strings.TrimSpace(r.Name) == "" {
+		return nil, errors.New("detector without a name")
+	}
+	if !signalNameRe.MatchString(r.Signal) {
+		return nil, fmt.Errorf("detector %s: signal %q must be lowercase snake_case", r.Name, r.Signal)
+	}
+	if r.Weight < 0 || r.Weight > 1 {
+		return nil, fmt.Errorf("detector %s: weight must be between 0 and 1", r.Name)
+	}
+	if r.Pattern == "" {
+		return nil, fmt.Errorf("detector %s: pattern is required", r.Name)
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("detector %s: %w", r.Name, err)
+	}
+	d := &RuleDetector{Rule: r, pattern: re}
+	for _, glob := range r.Paths {
+		d.paths = append(d.paths, globRegexp(glob))
+	}
+	return d, nil
+}
+
+// Spec registers the rule like a built-in detector.
+func (d *RuleDetector) Spec() Spec {
+	s := Spec{Name: d.Name, Detector: d, Weight: d.Weight, QuotesCode: captureRefRe.MatchString(d.Hint)}
+	for _, l := range d.Languages {
+		s.Languages = append(s.Languages, strings.ToLower(strings.TrimSpace(l)))
+	}
+	for _, f := range d.Frameworks {
+		s.Frameworks = append(s.Frameworks, strings.ToLower(strings.TrimSpace(f)))
+	}
+	if s.Weight == 0 {
+		s.Weight = defaultRuleWeight
+	}
+	return s
+}
+
+func (d *RuleDetector) Detect(line string, path string) (string, string, bool) {
+	if !d.matchesPath(path) {
+		return "", "", false
+	}
+	m := d.pattern.FindStringSubmatchIndex(line)
+	if m == nil {
+		return "", "", false
+	}
+	hint := string(d.pattern.ExpandString(nil, d.Hint, line, m))
+	return d.Signal, strings.TrimSpace(hint), true
+}
+
+// matchesPath reports whether the file matches one of the rule's globs.
+// Languages are checked by the rule's Spec.
+func (d *RuleDetector) matchesPath(path string) bool {
+	if len(d.paths) == 0 {
+		return true
+	}
+	path = filepath.ToSlash(path)
+	for i, re := range d.paths {
+		target := path
+		if !strings.Contains(d.Paths[i], "/") {
+			target = filepath.Base(path)
+		}
+		if re.MatchString(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// globRegexp translates a glob into an anchored regexp: "*" and "?" stay
+// within a path segment and "**" spans any number of them.
+func globRegexp(glob string) *regexp.Regexp {
+	glob = strings.TrimPrefix(filepath.ToSlash(glob), "/")
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// UserRulesDir is where user-defined detectors live: ~/.md2slack/detectors.
+func UserRulesDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".md2slack", "detectors")
+}
+
+// LoadRules reads every .yaml, .yml and .toml file in dir, in name order.
+// A missing directory has no detectors. Invalid files or rules are
+// reported in the error and skipped; the valid ones are still returned.
+func LoadRules(dir string) ([]Spec, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".toml":
+			if !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var specs []Spec
+	var errs []error
+	for _, name := range names {
+		rules, err := readRuleFile(filepath.Join(dir, name))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		for _, r := range rules {
+			d, err := NewRuleDetector(r)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			specs = append(specs, d.Spec())
+		}
+	}
+	return specs, errors.Join(errs...)
+}
+
+func readRuleFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f ruleFile
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		var md toml.MetaData
+		md, err = toml.Decode(string(data), &f)
+		if undecoded := md.Undecoded(); err == nil && len(undecoded) > 0 {
+			err = fmt.Errorf("unknown key %q", undecoded[0].String())
+		}
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(&f); errors.Is(err, io.EOF) {
+			err = nil // empty file
+		}
+	}
+	return f.Detectors, err
+}
@@ -0,0 +1,130 @@
+package hintdetector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"rails.yaml": `detectors:
+  - name: rails-migration
+    languages: [ruby]
+    paths: ["db/migrate/*.rb"]
+    pattern: 'create_table :(?P<table>\w+)'
+    signal: schema_change
+    hint: "creates table ${table}"
+`,
+		"django.toml": `# Django views
+[[detectors]]
+name = "django-view"
+paths = [
+  "**/views.py",  # any app
+]
+pattern = '^\s*def (\w+)\(request'
+signal = "route_change"
+hint = "view ${1}"
+frameworks = ["django"]
+weight = 0.8
+`,
+		"broken.yml": "detectors:\n  - name: bad\n    pattern: '('\n    signal: logic_change\n",
+		"notes.txt":  "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	specs, err := LoadRules(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.yml") {
+		t.Fatalf("expected broken.yml to be reported, got %v", err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("expected 2 valid detectors, got %d", len(specs))
+	}
+	if s := specs[0]; s.Name != "django-view" || s.Weight != 0.8 || !reflect.DeepEqual(s.Frameworks, []string{"django"}) {
+		t.Errorf("unexpected spec: %+v", s)
+	}
+	if s := specs[1]; s.Weight != defaultRuleWeight || !reflect.DeepEqual(s.Languages, []string{"ruby"}) {
+		t.Errorf("unexpected spec: %+v", s)
+	}
+
+	tests := []struct {
+		line, path   string
+		signal, hint string
+		found        bool
+	}{
+		{"    create_table :invoices do |t|", "db/migrate/20260101_create_invoices.rb", "schema_change", "creates table invoices", true},
+		{"    create_table :invoices do |t|", "db/seeds.rb", "", "", false},
+		{"def checkout(request):", "shop/orders/views.py", "route_change", "view checkout", true},
+		{"def checkout(request):", "views.py", "route_change", "view checkout", true},
+		{"def checkout(request):", "shop/orders/forms.py", "", "", false},
+		{"    create_table :invoices do |t|", "db/migrate/create_invoices.py", "", "", false},
+	}
+	for _, tt := range tests {
+		var signal, hint string
+		found := false
+		for _, s := range specs {
+			if !s.AppliesTo(tt.path) {
+				continue
+			}
+			if signal, hint, found = s.Detector.Detect(tt.line, tt.path); found {
+				break
+			}
+		}
+		if found != tt.found || signal != tt.signal || hint != tt.hint {
+			t.Errorf("Detect(%q, %q) = %q %q %v", tt.line, tt.path, signal, hint, found)
+		}
+	}
+}
+
+func TestRuleQuotesCode(t *testing.T) {
+	cases := map[string]bool{
+		"view ${1}":              true,
+		"creates table ${table}": true,
+		"touches $1":             true,
+		"costs $$5":              false,
+		"adds a retry":           false,
+		"":                       false,
+	}
+	for hint, want := range cases {
+		d, err := NewRuleDetector(Rule{Name: "r", Pattern: `(\w+)`, Signal: "logic_change", Hint: hint})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.Spec().QuotesCode; got != want {
+			t.Errorf("QuotesCode for hint %q = %v, want %v", hint, got, want)
+		}
+	}
+}
+
+func TestLoadRulesMissingDir(t *testing.T) {
+	detectors, err := LoadRules(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(detectors) != 0 {
+		t.Fatalf("expected no detectors, got %v %v", detectors, err)
+	}
+}
+
+func TestReadRuleFileTOMLErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.toml")
+	for _, src := range []string{
+		"name = \"x\"\n",
+		"[[detectors]]\nname = x\n",
+		"[[detectors]]\npaths = [\"a\"\n",
+		"[[detectors]]\ncolour = \"red\"\n",
+		"[settings]\n",
+		"[[detectors]]\nweight = high\n",
+	} {
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readRuleFile(path); err == nil {
+			t.Errorf("expected an error for %q", src)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package hintdetector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Stack is the languages and frameworks a repository uses.
+type Stack struct {
+	Languages  []string
+	Frameworks []string
+}
+
+// UsesAny reports whether the stack includes any of the frameworks.
+func (s Stack) UsesAny(frameworks []string) bool {
+	for _, f := range frameworks {
+		if slices.Contains(s.Frameworks, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// Framework markers in manifests, by the framework name detectors use.
+var (
+	npmFrameworks = map[string]string{
+		"next":           "nextjs",
+		"react":          "react",
+		"express":        "express",
+		"drizzle-orm":    "drizzle",
+		"prisma":         "prisma",
+		"@prisma/client": "prisma",
+		"vue":            "vue",
+		"svelte":         "svelte",
+	}
+	goFrameworks = map[string]string{
+		"github.com/gin-gonic/gin": "gin",
+		"github.com/labstack/echo": "echo",
+		"github.com/gofiber/fiber": "fiber",
+		"gorm.io/gorm":             "gorm",
+	}
+	pythonFrameworks = []string{"django", "flask", "fastapi", "sqlalchemy"}
+)
+
+// DetectStack reads the manifests at the root of a repository and in its
+// top-level directories: package.json, go.mod, requirements.txt,
+// pyproject.toml, Gemfile, pom.xml and build.gradle.
+func DetectStack(root string) Stack {
+	languages := make(map[string]bool)
+	frameworks := make(map[string]bool)
+
+	dirs := []string{root}
+	if entries, err := os.ReadDir(root); err == nil {
+		for _, e := range entries {
+			if e.IsDir() && !strings.HasPrefix(e.Name(), ".") && e.Name() != "node_modules" && e.Name() != "vendor" {
+				dirs = append(dirs, filepath.Join(root, e.Name()))
+			}
+		}
+	}
+	for _, dir := range dirs {
+		if deps, ok := npmDependencies(filepath.Join(dir, "package.json")); ok {
+			languages["javascript"] = true
+			if deps["typescript"] || fileExists(filepath.Join(dir, "tsconfig.json")) {
+				languages["typescript"] = true
+			}
+			for dep := range deps {
+				if f, ok := npmFrameworks[dep]; ok {
+					frameworks[f] = true
+				}
+			}
+		}
+		if requires, ok := readManifest(filepath.Join(dir, "go.mod")); ok {
+			languages["go"] = true
+			for module, f := range goFrameworks {
+				if strings.Contains(requires, module) {
+					frameworks[f] = true
+				}
+			}
+		}
+		for _, name := range []string{"requirements.txt", "pyproject.toml", "Pipfile"} {
+			if content, ok := readManifest(filepath.Join(dir, name)); ok {
+				languages["python"] = true
+				for _, f := range pythonFrameworks {
+					if strings.Contains(strings.ToLower(content), f) {
+						frameworks[f] = true
+					}
+				}
+			}
+		}
+		if content, ok := readManifest(filepath.Join(dir, "Gemfile")); ok {
+			languages["ruby"] = true
+			if strings.Contains(content, "rails") {
+				frameworks["rails"] = true
+			}
+		}
+		for _, name := range []string{"pom.xml", "build.gradle", "build.gradle.kts"} {
+			if content, ok := readManifest(filepath.Join(dir, name)); ok {
+				languages["java"] = true
+				if strings.Contains(content, "spring") {
+					frameworks["spring"] = true
+				}
+			}
+		}
+	}
+	return Stack{Languages: sortedNames(languages), Frameworks: sortedNames(frameworks)}
+}
+
+// npmDependencies returns the dependencies and devDependencies of a
+// package.json.
+func npmDependencies(path string) (map[string]bool, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	deps := make(map[string]bool)
+	if json.Unmarshal(data, &pkg) == nil {
+		for name := range pkg.Dependencies {
+			deps[name] = true
+		}
+		for name := range pkg.DevDependencies {
+			deps[name] = true
+		}
+	}
+	return deps, true
+}
+
+// readManifest returns the content of a manifest file, if it exists.
+func readManifest(path string) (string, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func sortedNames(set map[string]bool) []string {
+	var out []string
+	for name := range set {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,242 @@
+package issues
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"md2slack/internal/gitdiff"
+)
+
+const defaultGitHubAPI = "https://api.github.com"
+
+var (
+	repoSegmentRe = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	issueNumberRe = regexp.MustCompile(`^\d+$`)
+)
+
+// GitHubClient reads issues and pull requests from the GitHub REST API.
+// Repo ("owner/name") is used for bare "#123" keys; "owner/name#123" keys
+// name their own repository. BaseURL defaults to api.github.com.
+type GitHubClient struct {
+	BaseURL string
+	Repo    string
+	Token   string
+}
+
+func (c *GitHubClient) Name() string { return "github" }
+
+func (c *GitHubClient) Fetch(key string) (*Issue, error) {
+	repo, number, ok := strings.Cut(key, "#")
+	if !ok || number == "" {
+		return nil, fmt.Errorf("not a GitHub issue reference")
+	}
+	if repo == "" {
+		repo = c.Repo
+	}
+	if repo == "" {
+		return nil, fmt.Errorf("please configure repo in the [issues.github] section of config.ini")
+	}
+	path, err := repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+	if !issueNumberRe.MatchString(number) {
+		return nil, fmt.Errorf("invalid GitHub issue number %q", number)
+	}
+	req, err := c.newRequest(fmt.Sprintf("/repos/%s/issues/%s", path, number))
+	if err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		Title       string `json:"title"`
+		State       string `json:"state"`
+		HTMLURL     string `json:"html_url"`
+		PullRequest *struct {
+			MergedAt *string `json:"merged_at"`
+		} `json:"pull_request"`
+	}
+	if err := getJSON(req, &body); err != nil {
+		return nil, err
+	}
+	status := body.State
+	if body.PullRequest != nil && body.PullRequest.MergedAt != nil {
+		status = "merged"
+	}
+	return &Issue{Key: key, Title: body.Title, Status: status, URL: body.HTMLURL}, nil
+}
+
+// repoPath checks an "owner/name" taken from commit text and escapes it for
+// an API path, so a key cannot steer the authenticated request elsewhere.
+func repoPath(repo string) (string, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return "", fmt.Errorf("invalid GitHub repository %q", repo)
+	}
+	for _, seg := range []string{owner, name} {
+		if !repoSegmentRe.MatchString(seg) || seg == "." || seg == ".." {
+			return "", fmt.Errorf("invalid GitHub repository %q", repo)
+		}
+	}
+	return url.PathEscape(owner) + "/" + url.PathEscape(name), nil
+}
+
+func (c *GitHubClient) newRequest(path string) (*http.Request, error) {
+	base := strings.TrimRight(c.BaseURL, "/")
+	if base == "" {
+		base = defaultGitHubAPI
+	}
+	req, err := http.NewRequest(http.MethodGet, base+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return req, nil
+}
+
+// Merges returns the pull requests of Repo that user merged during day (in
+// local time). It covers squash and rebase merges, which leave no merge
+// commit behind.
+func (c *GitHubClient) Merges(user string, day time.Time) ([]gitdiff.Activity, error) {
+	if user == "" {
+		return nil, fmt.Errorf("please configure reviewer in the [sources] section of config.ini")
+	}
+	if c.Repo == "" {
+		return nil, fmt.Errorf("please configure repo in the [issues.github] section of config.ini")
+	}
+	path, err := repoPath(c.Repo)
+	if err != nil {
+		return nil, err
+	}
+	// Search cannot filter by who merged, so each merged pull request is
+	// read for its merged_by.
+	query := fmt.Sprintf("type:pr is:merged merged:>=%s repo:%s", day.Format("2006-01-02"), c.Repo)
+	req, err := c.newRequest("/search/issues?per_page=100&q=" + url.QueryEscape(query))
+	if err != nil {
+		return nil, err
+	}
+	var search struct {
+		Items []struct {
+			Number int `json:"number"`
+		} `json:"items"`
+	}
+	if err := getJSON(req, &search); err != nil {
+		return nil, err
+	}
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+	var out []gitdiff.Activity
+	for _, item := range search.Items {
+		req, err := c.newRequest(fmt.Sprintf("/repos/%s/pulls/%d", path, item.Number))
+		if err != nil {
+			return nil, err
+		}
+		var pr struct {
+			Title    string     `json:"title"`
+			HTMLURL  string     `json:"html_url"`
+			MergedAt *time.Time `json:"merged_at"`
+			User     struct {
+				Login string `json:"login"`
+			} `json:"user"`
+			MergedBy *struct {
+				Login string `json:"login"`
+			} `json:"merged_by"`
+		}
+		if err := getJSON(req, &pr); err != nil {
+			return nil, fmt.Errorf("pull request %s#%d: %w", c.Repo, item.Number, err)
+		}
+		if pr.MergedBy == nil || !strings.EqualFold(pr.MergedBy.Login, user) ||
+			pr.MergedAt == nil || pr.MergedAt.Before(start) || !pr.MergedAt.Before(end) {
+			continue
+		}
+		out = append(out, gitdiff.Activity{
+			Kind:   gitdiff.ActivityMerge,
+			Ref:    fmt.Sprintf("#%d", item.Number),
+			Title:  pr.Title,
+			Author: pr.User.Login,
+			URL:    pr.HTMLURL,
+			At:     *pr.MergedAt,
+		})
+	}
+	return out, nil
+}
+
+// Reviews returns the pull requests reviewer submitted a review on during
+// day (in local time). With Repo set, only that repository is searched.
+func (c *GitHubClient) Reviews(reviewer string, day time.Time) ([]gitdiff.Activity, error) {
+	if reviewer == "" {
+		return nil, fmt.Errorf("please configure reviewer in the [sources] section of config.ini")
+	}
+	query := fmt.Sprintf("type:pr reviewed-by:%s updated:>=%s", reviewer, day.Format("2006-01-02"))
+	if c.Repo != "" {
+		query += " repo:" + c.Repo
+	}
+	req, err := c.newRequest("/search/issues?per_page=100&q=" + url.QueryEscape(query))
+	if err != nil {
+		return nil, err
+	}
+	var search struct {
+		Items []struct {
+			Number        int    `json:"number"`
+			Title         string `json:"title"`
+			HTMLURL       string `json:"html_url"`
+			RepositoryURL string `json:"repository_url"`
+			User          struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		} `json:"items"`
+	}
+	if err := getJSON(req, &search); err != nil {
+		return nil, err
+	}
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+	var out []gitdiff.Activity
+	for _, item := range search.Items {
+		_, repo, ok := strings.Cut(item.RepositoryURL, "/repos/")
+		if !ok {
+			continue
+		}
+		req, err := c.newRequest(fmt.Sprintf("/repos/%s/pulls/%d/reviews?per_page=100", repo, item.Number))
+		if err != nil {
+			return nil, err
+		}
+		var reviews []struct {
+			User struct {
+				Login string `json:"login"`
+			} `json:"user"`
+			SubmittedAt time.Time `json:"submitted_at"`
+		}
+		if err := getJSON(req, &reviews); err != nil {
+			return nil, fmt.Errorf("reviews of %s#%d: %w", repo, item.Number, err)
+		}
+		for _, r := range reviews {
+			if !strings.EqualFold(r.User.Login, reviewer) || r.SubmittedAt.Before(start) || !r.SubmittedAt.Before(end) {
+				continue
+			}
+			ref := fmt.Sprintf("%s#%d", repo, item.Number)
+			if strings.EqualFold(repo, c.Repo) {
+				ref = fmt.Sprintf("#%d", item.Number)
+			}
+			out = append(out, gitdiff.Activity{
+				Kind:   gitdiff.ActivityReview,
+				Ref:    ref,
+				Title:  item.Title,
+				Author: item.User.Login,
+				URL:    item.HTMLURL,
+				At:     r.SubmittedAt,
+			})
+			break
+		}
+	}
+	return out, nil
+}
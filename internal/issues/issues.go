@@ -0,0 +1,158 @@
+package issues
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"md2slack/internal/config"
+	"md2slack/internal/gitdiff"
+)
+
+// Issue is the tracker data attached to an issue key.
+type Issue struct {
+	Key    string
+	Title  string
+	Status string
+	URL    string
+}
+
+// Client fetches issues from one tracker.
+type Client interface {
+	Name() string
+	Fetch(key string) (*Issue, error)
+}
+
+// Configured returns clients for the trackers with enrichment enabled, keyed
+// by tracker name.
+func Configured(cfg *config.Config) map[string]Client {
+	out := make(map[string]Client)
+	for _, ic := range cfg.Issues {
+		if !ic.Enrich {
+			continue
+		}
+		switch ic.Name {
+		case "jira":
+			out[ic.Name] = &JiraClient{BaseURL: ic.APIURL, Email: ic.Email, Token: ic.Token}
+		case "github":
+			out[ic.Name] = &GitHubClient{BaseURL: ic.APIURL, Repo: ic.Repo, Token: ic.Token}
+		}
+	}
+	return out
+}
+
+// ReviewSource returns the GitHub client used for code review activity, or
+// nil when reviews are disabled or no [issues.github] section is configured.
+func ReviewSource(cfg *config.Config) *GitHubClient {
+	if !cfg.Sources.Reviews {
+		return nil
+	}
+	return gitHubSource(cfg)
+}
+
+// MergeSource returns the GitHub client used for pull requests the user
+// merged, or nil when merges are disabled or no [issues.github] section is
+// configured.
+func MergeSource(cfg *config.Config) *GitHubClient {
+	if !cfg.Sources.Merges {
+		return nil
+	}
+	return gitHubSource(cfg)
+}
+
+func gitHubSource(cfg *config.Config) *GitHubClient {
+	for _, ic := range cfg.Issues {
+		if ic.Name == "github" {
+			return &GitHubClient{BaseURL: ic.APIURL, Repo: ic.Repo, Token: ic.Token}
+		}
+	}
+	return nil
+}
+
+// Enricher fills in issue titles and statuses, fetching each key once.
+type Enricher struct {
+	Clients map[string]Client
+
+	mu    sync.Mutex
+	cache map[string]fetchResult
+}
+
+type fetchResult struct {
+	issue *Issue
+	err   error
+}
+
+func NewEnricher(clients map[string]Client) *Enricher {
+	return &Enricher{Clients: clients, cache: make(map[string]fetchResult)}
+}
+
+// Enrich returns refs with Title and Status set from their trackers. Refs
+// whose tracker has no client are returned unchanged; fetch failures are
+// collected in the returned error and leave the ref unchanged.
+func (e *Enricher) Enrich(refs []gitdiff.IssueRef) ([]gitdiff.IssueRef, error) {
+	out := make([]gitdiff.IssueRef, len(refs))
+	var errs []error
+	for i, ref := range refs {
+		out[i] = ref
+		issue, err := e.fetch(ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if issue == nil {
+			continue
+		}
+		out[i].Title = issue.Title
+		out[i].Status = issue.Status
+		if out[i].URL == "" {
+			out[i].URL = issue.URL
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+func (e *Enricher) fetch(ref gitdiff.IssueRef) (*Issue, error) {
+	client, ok := e.Clients[ref.Tracker]
+	if !ok {
+		return nil, nil
+	}
+	cacheKey := ref.Tracker + "\x00" + ref.Key
+	e.mu.Lock()
+	res, cached := e.cache[cacheKey]
+	e.mu.Unlock()
+	if cached {
+		return res.issue, res.err
+	}
+	// Failures are cached too, so an unreachable tracker is only tried once
+	// per key.
+	issue, err := client.Fetch(ref.Key)
+	if err != nil {
+		err = fmt.Errorf("%s %s: %w", ref.Tracker, ref.Key, err)
+	}
+	e.mu.Lock()
+	e.cache[cacheKey] = fetchResult{issue: issue, err: err}
+	e.mu.Unlock()
+	return issue, err
+}
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// getJSON performs an authenticated GET and decodes the JSON response.
+func getJSON(req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,230 @@
+package issues
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"md2slack/internal/config"
+	"md2slack/internal/gitdiff"
+)
+
+func TestJiraClientFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/PROJ-12" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "bot@acme.io" || pass != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"key":"PROJ-12","fields":{"summary":"Retry webhook delivery","status":{"name":"In Progress"}}}`))
+	}))
+	defer srv.Close()
+
+	c := &JiraClient{BaseURL: srv.URL + "/", Email: "bot@acme.io", Token: "secret"}
+	issue, err := c.Fetch("PROJ-12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Title != "Retry webhook delivery" || issue.Status != "In Progress" || issue.URL != srv.URL+"/browse/PROJ-12" {
+		t.Fatalf("unexpected issue: %+v", issue)
+	}
+
+	if _, err := c.Fetch("PROJ-404"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected a 404 error, got %v", err)
+	}
+}
+
+func TestGitHubClientFetch(t *testing.T) {
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/repos/acme/app/issues/456":
+			w.Write([]byte(`{"title":"Checkout times out","state":"open","html_url":"https://github.com/acme/app/issues/456"}`))
+		case "/repos/acme/api/issues/7":
+			w.Write([]byte(`{"title":"Add retries","state":"closed","html_url":"https://github.com/acme/api/pull/7","pull_request":{"merged_at":"2026-02-05T10:00:00Z"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &GitHubClient{BaseURL: srv.URL, Repo: "acme/app", Token: "tok"}
+	issue, err := c.Fetch("#456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Title != "Checkout times out" || issue.Status != "open" {
+		t.Fatalf("unexpected issue: %+v", issue)
+	}
+	pr, err := c.Fetch("acme/api#7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Status != "merged" || pr.URL != "https://github.com/acme/api/pull/7" {
+		t.Fatalf("unexpected pull request: %+v", pr)
+	}
+	if auth[0] != "Bearer tok" {
+		t.Fatalf("expected bearer token, got %q", auth[0])
+	}
+
+	// Keys come from commit text: they must not steer the token elsewhere.
+	for _, key := range []string{"../../user#1", "acme/..#1", "acme/api/../x#1", "acme%2Fx/api#1", "acme/api#1?x=1", "ac me/api#1"} {
+		if _, err := c.Fetch(key); err == nil {
+			t.Errorf("expected %q to be rejected", key)
+		}
+	}
+	if len(auth) != 2 {
+		t.Fatalf("expected no requests for rejected keys, got %d", len(auth))
+	}
+}
+
+type countingClient struct {
+	calls int
+}
+
+func (c *countingClient) Name() string { return "jira" }
+
+func (c *countingClient) Fetch(key string) (*Issue, error) {
+	c.calls++
+	return &Issue{Key: key, Title: "Title of " + key, Status: "Done", URL: "https://jira/" + key}, nil
+}
+
+func TestEnricherCachesAndKeepsUnknownTrackers(t *testing.T) {
+	client := &countingClient{}
+	e := NewEnricher(map[string]Client{"jira": client})
+	refs := []gitdiff.IssueRef{
+		{Key: "PROJ-1", Tracker: "jira", URL: "https://acme.atlassian.net/browse/PROJ-1"},
+		{Key: "#2", Tracker: "github"},
+	}
+	for i := 0; i < 2; i++ {
+		got, err := e.Enrich(refs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[0].Title != "Title of PROJ-1" || got[0].Status != "Done" || got[0].URL != refs[0].URL {
+			t.Fatalf("unexpected enriched ref: %+v", got[0])
+		}
+		if got[1] != refs[1] {
+			t.Fatalf("ref without a client should be unchanged: %+v", got[1])
+		}
+	}
+	if client.calls != 1 {
+		t.Fatalf("expected one fetch, got %d", client.calls)
+	}
+}
+
+func TestConfigured(t *testing.T) {
+	cfg := &config.Config{Issues: []config.IssueTrackerConfig{
+		{Name: "jira", Enrich: true, APIURL: "https://acme.atlassian.net"},
+		{Name: "github"},
+		{Name: "linear", Enrich: true},
+	}}
+	clients := Configured(cfg)
+	if len(clients) != 1 || clients["jira"] == nil {
+		t.Fatalf("unexpected clients: %v", clients)
+	}
+}
+
+func TestGitHubSources(t *testing.T) {
+	cfg := &config.Config{Sources: config.SourcesConfig{Reviews: true}}
+	if gh := ReviewSource(cfg); gh != nil {
+		t.Fatalf("expected no review source without [issues.github], got %+v", gh)
+	}
+	cfg.Issues = []config.IssueTrackerConfig{{Name: "github", Repo: "acme/app", Token: "t"}}
+	if gh := ReviewSource(cfg); gh == nil || gh.Repo != "acme/app" {
+		t.Fatalf("unexpected review source: %+v", gh)
+	}
+	cfg.Sources.Reviews = false
+	if gh := ReviewSource(cfg); gh != nil {
+		t.Fatalf("expected no review source with reviews disabled, got %+v", gh)
+	}
+	if gh := MergeSource(cfg); gh != nil {
+		t.Fatalf("expected no merge source with merges disabled, got %+v", gh)
+	}
+	cfg.Sources.Merges = true
+	if gh := MergeSource(cfg); gh == nil || gh.Repo != "acme/app" {
+		t.Fatalf("unexpected merge source: %+v", gh)
+	}
+}
+
+func TestGitHubClientMerges(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/issues":
+			query = r.URL.Query().Get("q")
+			w.Write([]byte(`{"items":[{"number":15},{"number":16},{"number":17}]}`))
+		case "/repos/acme/app/pulls/15":
+			w.Write([]byte(`{"title":"Squash cart fixes","html_url":"https://github.com/acme/app/pull/15","merged_at":"2026-02-05T10:00:00Z","user":{"login":"bruno"},"merged_by":{"login":"Ana"}}`))
+		case "/repos/acme/app/pulls/16":
+			w.Write([]byte(`{"title":"Merged by someone else","merged_at":"2026-02-05T11:00:00Z","user":{"login":"bruno"},"merged_by":{"login":"carla"}}`))
+		case "/repos/acme/app/pulls/17":
+			w.Write([]byte(`{"title":"Merged the next day","merged_at":"2026-02-06T11:00:00Z","user":{"login":"bruno"},"merged_by":{"login":"ana"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &GitHubClient{BaseURL: srv.URL, Repo: "acme/app"}
+	acts, err := c.Merges("ana", time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "type:pr is:merged merged:>=2026-02-05 repo:acme/app" {
+		t.Fatalf("unexpected search query: %q", query)
+	}
+	if len(acts) != 1 {
+		t.Fatalf("expected one merge, got %+v", acts)
+	}
+	a := acts[0]
+	if a.Kind != gitdiff.ActivityMerge || a.Ref != "#15" || a.Title != "Squash cart fixes" || a.Author != "bruno" || a.URL != "https://github.com/acme/app/pull/15" {
+		t.Fatalf("unexpected merge activity: %+v", a)
+	}
+	if _, err := (&GitHubClient{BaseURL: srv.URL}).Merges("ana", time.Now()); err == nil {
+		t.Fatal("expected an error without a repo")
+	}
+}
+
+func TestGitHubClientReviews(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/issues":
+			query = r.URL.Query().Get("q")
+			w.Write([]byte(`{"items":[
+				{"number":7,"title":"Add caching","html_url":"https://github.com/acme/app/pull/7","repository_url":"` + "http://" + r.Host + `/repos/acme/app","user":{"login":"bruno"}},
+				{"number":9,"title":"Old review","html_url":"https://github.com/acme/lib/pull/9","repository_url":"` + "http://" + r.Host + `/repos/acme/lib","user":{"login":"carla"}}]}`))
+		case "/repos/acme/app/pulls/7/reviews":
+			w.Write([]byte(`[{"user":{"login":"bruno"},"submitted_at":"2026-02-05T09:00:00Z"},{"user":{"login":"Ana"},"submitted_at":"2026-02-05T14:00:00Z"}]`))
+		case "/repos/acme/lib/pulls/9/reviews":
+			w.Write([]byte(`[{"user":{"login":"ana"},"submitted_at":"2026-02-03T14:00:00Z"},{"user":{"login":"ana","submitted_at":null}}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &GitHubClient{BaseURL: srv.URL, Repo: "acme/app"}
+	day := time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC)
+	acts, err := c.Reviews("ana", day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "type:pr reviewed-by:ana updated:>=2026-02-05 repo:acme/app" {
+		t.Fatalf("unexpected search query: %q", query)
+	}
+	if len(acts) != 1 {
+		t.Fatalf("expected one review, got %+v", acts)
+	}
+	a := acts[0]
+	if a.Kind != gitdiff.ActivityReview || a.Ref != "#7" || a.Title != "Add caching" || a.Author != "bruno" || a.URL != "https://github.com/acme/app/pull/7" {
+		t.Fatalf("unexpected review activity: %+v", a)
+	}
+}
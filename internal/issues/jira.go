@@ -0,0 +1,59 @@
+package issues
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// JiraClient reads issues from the Jira REST API. BaseURL is the site root,
+// e.g. https://acme.atlassian.net. With Email set, Token is an API token used
+// with basic auth; otherwise it is sent as a bearer personal access token.
+type JiraClient struct {
+	BaseURL string
+	Email   string
+	Token   string
+}
+
+func (c *JiraClient) Name() string { return "jira" }
+
+func (c *JiraClient) Fetch(key string) (*Issue, error) {
+	if c.BaseURL == "" {
+		return nil, fmt.Errorf("please configure api_url in the [issues.jira] section of config.ini")
+	}
+	base := strings.TrimRight(c.BaseURL, "/")
+	endpoint := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=summary,status", base, url.PathEscape(key))
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c.Email != "":
+		req.SetBasicAuth(c.Email, c.Token)
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	var body struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	if err := getJSON(req, &body); err != nil {
+		return nil, err
+	}
+	if body.Key == "" {
+		body.Key = key
+	}
+	return &Issue{
+		Key:    body.Key,
+		Title:  body.Fields.Summary,
+		Status: body.Fields.Status.Name,
+		URL:    base + "/browse/" + body.Key,
+	}, nil
+}
@@ -29,6 +29,7 @@ func NewAgent(opts LLMOptions, taskTools *tools.TaskTools) *Agent {
 // Returns the response text and whether any tools were executed.
 func (a *Agent) StreamChat(history []OpenAIMessage, systemPrompt string) (string, bool, error) {
 	// Prepare messages
+	history, systemPrompt = a.Options.Privacy.anonymizeMessages(history, systemPrompt)
 	messages := convertToLLMCMessages(history, systemPrompt)
 
 	ctx := context.Background()
@@ -120,7 +121,7 @@ func (a *Agent) StreamChat(history []OpenAIMessage, systemPrompt string) (string
 
 				tool, found := a.Tools.Find(tc.FunctionCall.Name)
 				if found {
-					result, toolErr = tool.Call(ctx, tc.FunctionCall.Arguments)
+					result, toolErr = tool.Call(ctx, a.Options.Privacy.restoreText(tc.FunctionCall.Arguments))
 				} else {
 					result = "Error: Tool not found"
 					toolErr = fmt.Errorf("tool not found: %s", tc.FunctionCall.Name)
@@ -142,7 +143,7 @@ func (a *Agent) StreamChat(history []OpenAIMessage, systemPrompt string) (string
 						llms.ToolCallResponse{
 							ToolCallID: tc.ID,
 							Name:       tc.FunctionCall.Name,
-							Content:    result,
+							Content:    a.Options.Privacy.anonymizeText(result),
 						},
 					},
 				})
@@ -153,7 +154,7 @@ func (a *Agent) StreamChat(history []OpenAIMessage, systemPrompt string) (string
 		}
 
 		// No tool calls, we are done
-		return responseText, toolUsed, nil
+		return a.Options.Privacy.restoreText(responseText), toolUsed, nil
 	}
 
 	return "Max turns reached", toolUsed, nil
@@ -163,6 +164,7 @@ func (a *Agent) StreamChat(history []OpenAIMessage, systemPrompt string) (string
 // Returns parsed tool calls and the raw response text.
 func (a *Agent) ForceToolCalls(history []OpenAIMessage, systemPrompt string) ([]ToolCall, string, error) {
 	forcedSystem := systemPrompt + "\n\nIMPORTANT: Respond ONLY with tool calls. Do not include any prose."
+	history, forcedSystem = a.Options.Privacy.anonymizeMessages(history, forcedSystem)
 	messages := convertToLLMCMessages(history, forcedSystem)
 
 	ctx := context.Background()
@@ -206,19 +208,20 @@ func (a *Agent) ForceToolCalls(history []OpenAIMessage, systemPrompt string) ([]
 	if responseText == "" && streamBuf.Len() > 0 {
 		responseText = streamBuf.String()
 	}
+	responseText = a.Options.Privacy.restoreText(responseText)
 
 	if len(choice.ToolCalls) > 0 {
-		return convertToolCalls(choice.ToolCalls), responseText, nil
+		return convertToolCalls(choice.ToolCalls, a.Options.Privacy), responseText, nil
 	}
 
 	return parseToolCallsFromText(responseText), responseText, nil
 }
 
-func convertToolCalls(calls []llms.ToolCall) []ToolCall {
+func convertToolCalls(calls []llms.ToolCall, privacy *Privacy) []ToolCall {
 	var out []ToolCall
 	for _, tc := range calls {
 		var params map[string]interface{}
-		_ = json.Unmarshal([]byte(tc.FunctionCall.Arguments), &params)
+		_ = json.Unmarshal([]byte(privacy.restoreText(tc.FunctionCall.Arguments)), &params)
 		out = append(out, ToolCall{
 			Tool:       tc.FunctionCall.Name,
 			Parameters: params,
@@ -36,6 +36,9 @@ type LLMOptions struct {
 	OnToolStart   func(string, string) // Called when a tool starts: (toolName, toolID)
 	OnToolEnd     func(string, string) // Called when a tool ends: (toolName, result)
 	Timeout       time.Duration
+	// Privacy limits what prompts include of the repository; nil sends
+	// everything.
+	Privacy *Privacy
 }
 
 // OpenAIMessage is now shared with webui, but we keep it here for internal use.
@@ -107,23 +110,30 @@ func ExtractCommitIntent(change gitdiff.SemanticChange, commitMsg string, option
 		return nil, errors.New("prompt file commit_intent_extractor.txt not found")
 	}
 
-	prompt := fmt.Sprintf("Commit: %s\nMessage: %s\nSignals: %v", change.CommitHash, commitMsg, change.Signals)
-
 	var out gitdiff.CommitChange
-	messages := []OpenAIMessage{{Role: "user", Content: prompt}}
+	messages := []OpenAIMessage{{Role: "user", Content: commitIntentPrompt(change, commitMsg, options.Privacy)}}
 	err := callJSON(messages, system, options, &out)
 	return &out, err
 }
 
+// commitIntentPrompt describes a commit by its ranked signal summary rather
+// than every per-file signal, so large commits stay short.
+func commitIntentPrompt(change gitdiff.SemanticChange, commitMsg string, privacy *Privacy) string {
+	return fmt.Sprintf("Commit: %s\nMessage: %s\n%s", change.CommitHash, commitMsg, privacy.signalsPrompt(change.Signals))
+}
+
 func SummarizeCommit(commit gitdiff.Commit, diff gitdiff.CommitDiff, semantic gitdiff.CommitSemantic, options LLMOptions) (*gitdiff.CommitSummary, error) {
 	system := readPromptFile("commit_summarizer.txt")
 	if system == "" {
 		return nil, errors.New("prompt file commit_summarizer.txt not found")
 	}
 
-	semanticJSON, _ := json.MarshalIndent(semantic, "", "  ")
-	prompt := fmt.Sprintf("Commit: %s\nMessage: %s\nSemantic (JSON): %s\nRaw Diff:\n%s",
-		commit.Hash, commit.Message, string(semanticJSON), diff.Diff)
+	semanticJSON, _ := json.MarshalIndent(options.Privacy.semantic(semantic), "", "  ")
+	prompt := fmt.Sprintf("Commit: %s\nMessage: %s\nSemantic (JSON): %s",
+		commit.Hash, commit.Message, string(semanticJSON))
+	if options.Privacy.AllowsCode() {
+		prompt += "\nRaw Diff:\n" + diff.Diff
+	}
 
 	var out gitdiff.CommitSummary
 	messages := []OpenAIMessage{{Role: "user", Content: prompt}}
@@ -223,7 +233,7 @@ func IncorporateExtraContext(extraContext string, options LLMOptions) ([]gitdiff
 		// Apply tools
 		var log string
 		var status string
-		currentTasks, log, status = ApplyTools(tools, currentTasks, nil)
+		currentTasks, log, status = applyTools(tools, currentTasks, nil, options.Privacy)
 		emitToolUpdates(options, log, status)
 		for i := range currentTasks {
 			currentTasks[i].IsManual = true
@@ -255,9 +265,9 @@ func GenerateTasksFromContext(commits []gitdiff.Commit, summaries []gitdiff.Comm
 		return nil, errors.New("prompt file task_tools_generate.txt not found")
 	}
 
-	commitsJSON, _ := json.MarshalIndent(commits, "", "  ")
+	commitsJSON, _ := json.MarshalIndent(options.Privacy.commits(commits), "", "  ")
 	summaryJSON, _ := json.MarshalIndent(summaries, "", "  ")
-	semanticJSON, _ := json.MarshalIndent(semantics, "", "  ")
+	semanticJSON, _ := json.MarshalIndent(options.Privacy.semantics(semantics), "", "  ")
 	allowedList := sortedCommitList(allowedCommits)
 	allowedText := "(none)"
 	if len(allowedList) > 0 {
@@ -282,7 +292,7 @@ func GenerateTasksFromContext(commits []gitdiff.Commit, summaries []gitdiff.Comm
 
 		var log string
 		var status string
-		currentTasks, log, status = ApplyTools(tools, currentTasks, allowedCommits)
+		currentTasks, log, status = applyTools(tools, currentTasks, allowedCommits, options.Privacy)
 		emitToolUpdates(options, log, status)
 
 		showStateDashboard("Task Gen", currentTasks, log, turn, options.Quiet)
@@ -318,9 +328,9 @@ func ReviewTasks(currentTasks []gitdiff.TaskChange, commits []gitdiff.Commit, su
 		return nil, errors.New("prompt file task_tools_review.txt not found")
 	}
 
-	commitsJSON, _ := json.MarshalIndent(commits, "", "  ")
+	commitsJSON, _ := json.MarshalIndent(options.Privacy.commits(commits), "", "  ")
 	summaryJSON, _ := json.MarshalIndent(summaries, "", "  ")
-	semanticJSON, _ := json.MarshalIndent(semantics, "", "  ")
+	semanticJSON, _ := json.MarshalIndent(options.Privacy.semantics(semantics), "", "  ")
 	tasksJSON, _ := json.MarshalIndent(currentTasks, "", "  ")
 	allowedList := sortedCommitList(allowedCommits)
 	allowedText := "(none)"
@@ -345,7 +355,7 @@ func ReviewTasks(currentTasks []gitdiff.TaskChange, commits []gitdiff.Commit, su
 
 		var log string
 		var status string
-		currentTasks, log, status = ApplyTools(tools, currentTasks, allowedCommits)
+		currentTasks, log, status = applyTools(tools, currentTasks, allowedCommits, options.Privacy)
 		emitToolUpdates(options, log, status)
 
 		showStateDashboard("Task Review", currentTasks, log, turn, options.Quiet)
@@ -392,7 +402,22 @@ func IncorporateCommit(commit gitdiff.CommitChange, currentTasks []gitdiff.TaskC
 
 	var sb strings.Builder
 	for i, t := range currentTasks {
-		sb.WriteString(fmt.Sprintf("[%d] %s (%s) [%s]\n", i, t.TaskIntent, t.Scope, t.TaskType))
+		sb.WriteString(fmt.Sprintf("[%d] %s (%s) [%s]", i, t.TaskIntent, t.Scope, t.TaskType))
+		if len(t.Issues) > 0 {
+			var refs []string
+			for _, ref := range t.Issues {
+				if ref.Title != "" {
+					refs = append(refs, fmt.Sprintf("%s %q", ref.Key, ref.Title))
+				} else {
+					refs = append(refs, ref.Key)
+				}
+			}
+			sb.WriteString(fmt.Sprintf(" {issues: %s}", strings.Join(refs, ", ")))
+		}
+		if t.Branch != "" {
+			sb.WriteString(fmt.Sprintf(" {branch: %s}", t.Branch))
+		}
+		sb.WriteString("\n")
 		if t.TechnicalWhy != "" {
 			parts := strings.Split(t.TechnicalWhy, "\n")
 			for _, p := range parts {
@@ -431,7 +456,7 @@ func IncorporateCommit(commit gitdiff.CommitChange, currentTasks []gitdiff.TaskC
 		// Apply tools and update state
 		var log string
 		var status string
-		currentTasks, log, status = ApplyTools(tools, currentTasks, allowedCommits)
+		currentTasks, log, status = applyTools(tools, currentTasks, allowedCommits, options.Privacy)
 		emitToolUpdates(options, log, status)
 
 		// REAL-TIME VISUALIZATION: Show the state dashboard to the user
@@ -751,6 +776,12 @@ func indexInSorted(val int, sorted []int) bool {
 }
 
 func ApplyTools(tools []ToolCall, tasks []gitdiff.TaskChange, allowedCommits map[string]struct{}) ([]gitdiff.TaskChange, string, string) {
+	return applyTools(tools, tasks, allowedCommits, nil)
+}
+
+// applyTools is ApplyTools for a repository's privacy settings: below the
+// full level, get_codebase_context is refused.
+func applyTools(tools []ToolCall, tasks []gitdiff.TaskChange, allowedCommits map[string]struct{}, privacy *Privacy) ([]gitdiff.TaskChange, string, string) {
 	var logs []string
 	var status string
 	for _, tc := range tools {
@@ -874,6 +905,10 @@ func ApplyTools(tools []ToolCall, tasks []gitdiff.TaskChange, allowedCommits map
 				logs = append(logs, "Error: get_codebase_context requires non-empty query")
 				continue
 			}
+			if !privacy.AllowsCode() {
+				logs = append(logs, "Error: get_codebase_context is disabled by this repository's privacy level")
+				continue
+			}
 			out, err := getCodebaseContext(query, path, maxResults)
 			if err != nil {
 				logs = append(logs, fmt.Sprintf("Error: get_codebase_context failed: %v", err))
@@ -1033,7 +1068,9 @@ func getCodebaseContext(query string, path string, maxResults int) (string, erro
 		}
 		return "", err
 	}
-	return strings.TrimSpace(out.String()), nil
+	// Search results go to the model like diffs do
+	redacted, _ := gitdiff.RedactSecrets(strings.TrimSpace(out.String()))
+	return redacted, nil
 }
 
 func toolErrorSummary(log string) string {
@@ -1220,6 +1257,7 @@ func convertToLLMCMessages(messages []OpenAIMessage, system string) []llms.Messa
 }
 
 func callJSON(messages []OpenAIMessage, system string, options LLMOptions, target interface{}, tools ...llms.Tool) error {
+	messages, system = options.Privacy.anonymizeMessages(messages, system)
 	llmsMessages := convertToLLMCMessages(messages, system)
 	payload := formatMessages(messages)
 	if system != "" {
@@ -1302,6 +1340,7 @@ func callJSON(messages []OpenAIMessage, system string, options LLMOptions, targe
 	fmt.Printf("[callJSON] Response - Text length: %d, Tool calls: %d\n", len(responseText), len(toolCalls))
 
 	emitLLMLog(options, "LLM OUTPUT", responseText)
+	responseText = options.Privacy.restoreText(responseText)
 	if len(toolCalls) > 0 {
 		emitLLMLog(options, "LLM TOOL CALLS", fmt.Sprintf("%d calls", len(toolCalls)))
 		for i, tc := range toolCalls {
@@ -1323,7 +1362,7 @@ func callJSON(messages []OpenAIMessage, system string, options LLMOptions, targe
 		var nativeTools []ToolCall
 		for _, tc := range toolCalls {
 			var params map[string]interface{}
-			_ = json.Unmarshal([]byte(tc.FunctionCall.Arguments), &params)
+			_ = json.Unmarshal([]byte(options.Privacy.restoreText(tc.FunctionCall.Arguments)), &params)
 			nativeTools = append(nativeTools, ToolCall{
 				Tool:       tc.FunctionCall.Name,
 				Parameters: params,
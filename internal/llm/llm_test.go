@@ -3,6 +3,8 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"md2slack/internal/gitdiff"
+	"strings"
 	"testing"
 )
 
@@ -66,3 +68,28 @@ func TestToolCallUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestCommitIntentPromptUsesSignalSummary(t *testing.T) {
+	prompt := commitIntentPrompt(gitdiff.SemanticChange{
+		CommitHash: "abc123",
+		Signals: []gitdiff.Signal{{
+			File:    "api/orders.ts",
+			Types:   []gitdiff.SignalType{gitdiff.SignalTimeoutChange},
+			Hints:   []string{"timeout adjusted"},
+			Weights: map[gitdiff.SignalType]float64{gitdiff.SignalTimeoutChange: 0.6},
+			Counts:  map[gitdiff.SignalType]int{gitdiff.SignalTimeoutChange: 2},
+		}},
+	}, "raise order timeout", nil)
+
+	_, payload, ok := strings.Cut(prompt, "Signals (JSON): ")
+	if !ok {
+		t.Fatalf("missing signals in prompt: %q", prompt)
+	}
+	var sum gitdiff.SignalSummary
+	if err := json.Unmarshal([]byte(payload), &sum); err != nil {
+		t.Fatalf("signals are not JSON: %v\n%s", err, payload)
+	}
+	if len(sum.Signals) != 1 || sum.Signals[0].Count != 2 || len(sum.Files) != 1 || sum.Files[0].Hints[0] != "timeout adjusted" {
+		t.Fatalf("unexpected summary: %+v", sum)
+	}
+}
@@ -0,0 +1,298 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"md2slack/internal/gitdiff"
+	"md2slack/internal/hintdetector"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PrivacyLevel limits what prompts reveal about a repository's code.
+type PrivacyLevel string
+
+const (
+	// PrivacyFull sends diffs and every signal.
+	PrivacyFull PrivacyLevel = "full"
+	// PrivacySignals sends commit messages and ranked signal summaries,
+	// never code.
+	PrivacySignals PrivacyLevel = "signals"
+	// PrivacyMetadata sends commit messages and changed files counted by
+	// type.
+	PrivacyMetadata PrivacyLevel = "metadata"
+)
+
+// ParsePrivacyLevel reads a configured level; empty means full. An unknown
+// level returns PrivacyMetadata along with the error, so a typo never sends
+// more than intended.
+func ParsePrivacyLevel(s string) (PrivacyLevel, error) {
+	switch l := PrivacyLevel(strings.ToLower(strings.TrimSpace(s))); l {
+	case "":
+		return PrivacyFull, nil
+	case PrivacyFull, PrivacySignals, PrivacyMetadata:
+		return l, nil
+	}
+	return PrivacyMetadata, fmt.Errorf("unknown privacy level %q (want full, signals or metadata)", s)
+}
+
+// Privacy is applied to every prompt built from a repository's facts. With
+// path anonymisation, the paths given to AddFiles are replaced by aliases
+// such as "file3.go" in everything sent to the LLM, and the aliases are
+// turned back into paths in everything it returns. A nil *Privacy sends
+// everything as is.
+type Privacy struct {
+	Level PrivacyLevel
+
+	mu        sync.RWMutex
+	aliases   map[string]string // path to alias; nil without anonymisation
+	anonymize *strings.Replacer
+	restore   *strings.Replacer
+}
+
+// NewPrivacy returns the privacy settings for one run over a repository.
+func NewPrivacy(level PrivacyLevel, anonymizePaths bool) *Privacy {
+	p := &Privacy{Level: level}
+	if anonymizePaths {
+		p.aliases = make(map[string]string)
+	}
+	return p
+}
+
+func (p *Privacy) level() PrivacyLevel {
+	if p == nil || p.Level == "" {
+		return PrivacyFull
+	}
+	return p.Level
+}
+
+// AllowsCode reports whether prompts and tools may include code.
+func (p *Privacy) AllowsCode() bool {
+	return p.level() == PrivacyFull
+}
+
+// AddFiles gives the path of each file, and the source of renames, an
+// alias that keeps only the extension. It does nothing without path
+// anonymisation.
+func (p *Privacy) AddFiles(files []gitdiff.DiffFile) {
+	if p == nil || p.aliases == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, f := range files {
+		for _, name := range []string{f.Path, f.OldPath} {
+			if _, ok := p.aliases[name]; ok || name == "" {
+				continue
+			}
+			p.aliases[name] = fmt.Sprintf("file%d%s", len(p.aliases)+1, path.Ext(name))
+		}
+	}
+
+	// Longer strings first, so a path is never replaced by the alias of a
+	// path it ends with, nor file1 by half of file12.
+	paths := make([]string, 0, len(p.aliases))
+	for name := range p.aliases {
+		paths = append(paths, name)
+	}
+	sort.Slice(paths, func(i, j int) bool { return len(paths[i]) > len(paths[j]) })
+	aliases := make([]string, 0, len(paths))
+	for _, name := range paths {
+		aliases = append(aliases, p.aliases[name])
+	}
+	sort.Slice(aliases, func(i, j int) bool { return len(aliases[i]) > len(aliases[j]) })
+
+	var toAlias, toPath []string
+	for _, name := range paths {
+		toAlias = append(toAlias, name, p.aliases[name])
+	}
+	byAlias := make(map[string]string, len(p.aliases))
+	for name, alias := range p.aliases {
+		byAlias[alias] = name
+	}
+	for _, alias := range aliases {
+		toPath = append(toPath, alias, byAlias[alias])
+	}
+	p.anonymize = strings.NewReplacer(toAlias...)
+	p.restore = strings.NewReplacer(toPath...)
+}
+
+// anonymizeText replaces known paths with their aliases.
+func (p *Privacy) anonymizeText(s string) string {
+	if p == nil {
+		return s
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.anonymize == nil {
+		return s
+	}
+	return p.anonymize.Replace(s)
+}
+
+// restoreText replaces aliases with the paths they stand for.
+func (p *Privacy) restoreText(s string) string {
+	if p == nil {
+		return s
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.restore == nil {
+		return s
+	}
+	return p.restore.Replace(s)
+}
+
+// anonymizeMessages returns a copy of a conversation and its system prompt
+// with known paths replaced by their aliases.
+func (p *Privacy) anonymizeMessages(messages []OpenAIMessage, system string) ([]OpenAIMessage, string) {
+	out := make([]OpenAIMessage, len(messages))
+	for i, m := range messages {
+		out[i] = OpenAIMessage{Role: m.Role, Content: p.anonymizeText(m.Content)}
+	}
+	return out, p.anonymizeText(system)
+}
+
+// fileType names a file's language, or failing that its extension.
+func fileType(name string) string {
+	if l := hintdetector.Language(name); l != "" {
+		return l
+	}
+	if ext := strings.TrimPrefix(strings.ToLower(path.Ext(name)), "."); ext != "" {
+		return ext
+	}
+	return "other"
+}
+
+// fileTypes counts files by type.
+func fileTypes(paths []string) map[string]int {
+	if len(paths) == 0 {
+		return nil
+	}
+	out := make(map[string]int)
+	for _, name := range paths {
+		out[fileType(name)]++
+	}
+	return out
+}
+
+func signalFiles(signals []gitdiff.Signal) []string {
+	var out []string
+	for _, s := range signals {
+		if s.File != "" {
+			out = append(out, s.File)
+		}
+	}
+	return out
+}
+
+// withoutCode returns copies of signals without what quotes the changed
+// code: the hunk header lines of locations and hints filled in from capture
+// groups.
+func withoutCode(signals []gitdiff.Signal) []gitdiff.Signal {
+	out := make([]gitdiff.Signal, len(signals))
+	for i, s := range signals {
+		out[i] = s
+		out[i].Hints = nil
+		for _, h := range s.Hints {
+			if !slices.Contains(s.CodeHints, h) {
+				out[i].Hints = append(out[i].Hints, h)
+			}
+		}
+		out[i].CodeHints = nil
+		out[i].Locations = make([]gitdiff.Location, len(s.Locations))
+		for j, loc := range s.Locations {
+			loc.Section = ""
+			out[i].Locations[j] = loc
+		}
+	}
+	return out
+}
+
+// signalsPrompt describes a commit's signals: ranked, or at the metadata
+// level only its files counted by type.
+func (p *Privacy) signalsPrompt(signals []gitdiff.Signal) string {
+	if p.level() == PrivacyMetadata {
+		typesJSON, _ := json.Marshal(fileTypes(signalFiles(signals)))
+		return fmt.Sprintf("Files by type (JSON): %s", typesJSON)
+	}
+	if !p.AllowsCode() {
+		signals = withoutCode(signals)
+	}
+	summaryJSON, _ := json.Marshal(gitdiff.SummarizeSignals(signals))
+	return fmt.Sprintf("Signals (JSON): %s", summaryJSON)
+}
+
+// promptCommit is a commit as sent below the full level: its message only.
+type promptCommit struct {
+	Hash      string         `json:"hash"`
+	Message   string         `json:"message"`
+	Body      string         `json:"body,omitempty"`
+	FileTypes map[string]int `json:"file_types,omitempty"`
+}
+
+// commits returns what prompts may include of commits.
+func (p *Privacy) commits(commits []gitdiff.Commit) any {
+	if p.AllowsCode() {
+		return commits
+	}
+	out := make([]promptCommit, 0, len(commits))
+	for _, c := range commits {
+		pc := promptCommit{Hash: c.Hash, Message: c.Message, Body: c.Body}
+		if p.level() == PrivacyMetadata {
+			paths := make([]string, 0, len(c.Files))
+			for _, f := range c.Files {
+				paths = append(paths, f.Path)
+			}
+			pc.FileTypes = fileTypes(paths)
+		}
+		out = append(out, pc)
+	}
+	return out
+}
+
+// promptSemantic is a commit's semantic facts as sent below the full
+// level: ranked signals, or at the metadata level files counted by type.
+type promptSemantic struct {
+	CommitHash     string                 `json:"commit"`
+	Signals        *gitdiff.SignalSummary `json:"signals,omitempty"`
+	FileTypes      map[string]int         `json:"file_types,omitempty"`
+	FilesTouched   int                    `json:"files_touched"`
+	SessionMinutes int                    `json:"session_minutes,omitempty"`
+	IssueKeys      []string               `json:"issue_keys,omitempty"`
+	Branch         string                 `json:"branch,omitempty"`
+}
+
+func (p *Privacy) semantic(sem gitdiff.CommitSemantic) any {
+	if p.AllowsCode() {
+		return sem
+	}
+	out := promptSemantic{
+		CommitHash:     sem.CommitHash,
+		FilesTouched:   sem.FilesTouched,
+		SessionMinutes: sem.SessionMinutes,
+		IssueKeys:      sem.IssueKeys,
+		Branch:         sem.Branch,
+	}
+	if p.level() == PrivacyMetadata {
+		out.FileTypes = fileTypes(signalFiles(sem.Signals))
+	} else {
+		summary := gitdiff.SummarizeSignals(withoutCode(sem.Signals))
+		out.Signals = &summary
+	}
+	return out
+}
+
+func (p *Privacy) semantics(semantics []gitdiff.CommitSemantic) any {
+	if p.AllowsCode() {
+		return semantics
+	}
+	out := make([]any, 0, len(semantics))
+	for _, sem := range semantics {
+		out = append(out, p.semantic(sem))
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"md2slack/internal/gitdiff"
+	"strings"
+	"testing"
+)
+
+func TestParsePrivacyLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    PrivacyLevel
+		wantErr bool
+	}{
+		{"", PrivacyFull, false},
+		{"full", PrivacyFull, false},
+		{" Signals ", PrivacySignals, false},
+		{"METADATA", PrivacyMetadata, false},
+		{"none", PrivacyMetadata, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePrivacyLevel(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParsePrivacyLevel(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestPrivacyAnonymizesAndRestoresPaths(t *testing.T) {
+	p := NewPrivacy(PrivacyFull, true)
+	files := []gitdiff.DiffFile{{Path: "acme/billing/invoice.go", OldPath: "acme/invoice.go", IsRename: true}}
+	for i := 0; i < 11; i++ {
+		files = append(files, gitdiff.DiffFile{Path: fmt.Sprintf("acme/pkg%d/x", i)})
+	}
+	p.AddFiles(files)
+
+	text := "Edited acme/billing/invoice.go (was acme/invoice.go) and acme/pkg10/x, acme/pkg0/x"
+	anon := p.anonymizeText(text)
+	if strings.Contains(anon, "acme") {
+		t.Fatalf("expected every path replaced, got %q", anon)
+	}
+	if !strings.Contains(anon, "file1.go") || !strings.Contains(anon, "file2.go") {
+		t.Fatalf("expected aliases to keep the extension, got %q", anon)
+	}
+	if got := p.restoreText(anon); got != text {
+		t.Fatalf("restore = %q, want %q", got, text)
+	}
+
+	// Without anonymisation, text goes through untouched.
+	plain := NewPrivacy(PrivacyFull, false)
+	plain.AddFiles(files)
+	if got := plain.anonymizeText(text); got != text {
+		t.Fatalf("expected no aliases, got %q", got)
+	}
+	var none *Privacy
+	if got := none.restoreText(text); got != text {
+		t.Fatalf("expected nil privacy to be a no-op, got %q", got)
+	}
+}
+
+func TestPrivacyLevelsLimitPrompts(t *testing.T) {
+	signals := []gitdiff.Signal{
+		{
+			File:      "api/orders.ts",
+			Types:     []gitdiff.SignalType{gitdiff.SignalTimeoutChange, "payment"},
+			Hints:     []string{"timeout adjusted", "charges via stripe.charges.create(order.total)"},
+			CodeHints: []string{"charges via stripe.charges.create(order.total)"},
+			Locations: []gitdiff.Location{{Type: gitdiff.SignalTimeoutChange, Line: 12, Section: "async function chargeCard(order: Order) {", Edit: "modified"}},
+		},
+		{File: "api/orders_client.ts", Types: []gitdiff.SignalType{gitdiff.SignalNewFile}},
+		{File: "README.md"},
+	}
+	change := gitdiff.SemanticChange{CommitHash: "abc123", Signals: signals}
+	commits := []gitdiff.Commit{{
+		Hash:    "abc123",
+		Message: "Raise order timeout",
+		Files:   []gitdiff.DiffFile{{Path: "api/orders.ts", Additions: []string{"const timeout = 30_000"}}},
+	}}
+	semantics := []gitdiff.CommitSemantic{{CommitHash: "abc123", Signals: signals, FilesTouched: 3}}
+
+	prompt := func(level PrivacyLevel) string {
+		p := NewPrivacy(level, false)
+		commitsJSON, _ := json.Marshal(p.commits(commits))
+		semanticJSON, _ := json.Marshal(p.semantics(semantics))
+		return commitIntentPrompt(change, "Raise order timeout", p) + "\n" + string(commitsJSON) + "\n" + string(semanticJSON)
+	}
+
+	full := prompt(PrivacyFull)
+	if !strings.Contains(full, "const timeout") || !strings.Contains(full, "timeout adjusted") ||
+		!strings.Contains(full, "chargeCard") || !strings.Contains(full, "stripe.charges") {
+		t.Fatalf("expected code and signals at the full level: %s", full)
+	}
+
+	sig := prompt(PrivacySignals)
+	for _, leak := range []string{"const timeout", "chargeCard", "stripe.charges"} {
+		if strings.Contains(sig, leak) {
+			t.Fatalf("expected no code (%q) at the signals level: %s", leak, sig)
+		}
+	}
+	if !strings.Contains(sig, `"line":12`) {
+		t.Fatalf("expected locations without their code at the signals level: %s", sig)
+	}
+	if !strings.Contains(sig, "timeout adjusted") || !strings.Contains(sig, "Raise order timeout") {
+		t.Fatalf("expected signal summaries and messages at the signals level: %s", sig)
+	}
+
+	meta := prompt(PrivacyMetadata)
+	for _, leak := range []string{"const timeout", "timeout adjusted", "chargeCard", "stripe.charges", "api/orders", "README"} {
+		if strings.Contains(meta, leak) {
+			t.Fatalf("expected no %q at the metadata level: %s", leak, meta)
+		}
+	}
+	if !strings.Contains(meta, `"typescript":2`) || !strings.Contains(meta, `"md":1`) || !strings.Contains(meta, "Raise order timeout") {
+		t.Fatalf("expected file-type counts and messages at the metadata level: %s", meta)
+	}
+}
+
+func TestApplyToolsRefusesCodeSearchBelowFull(t *testing.T) {
+	calls := []ToolCall{{Tool: "get_codebase_context", Parameters: map[string]interface{}{"query": "timeout"}}}
+	_, log, _ := applyTools(calls, nil, nil, NewPrivacy(PrivacySignals, false))
+	if !strings.Contains(log, "disabled by this repository's privacy level") {
+		t.Fatalf("expected the code search to be refused, got %q", log)
+	}
+}
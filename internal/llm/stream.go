@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -9,26 +10,29 @@ import (
 	"strings"
 )
 
-// StreamChatWithRequests handles streaming chat with tool calls
-func StreamChatWithRequests(history []OpenAIMessage, currentTasks []gitdiff.TaskChange, options LLMOptions, allowedCommits map[string]struct{}) ([]gitdiff.TaskChange, string, error) {
+// StreamChatWithRequests handles streaming chat with tool calls.
+// Blockers are returned with any additions and resolutions made during the chat.
+func StreamChatWithRequests(history []OpenAIMessage, currentTasks []gitdiff.TaskChange, currentBlockers []gitdiff.Blocker, date string, options LLMOptions, allowedCommits map[string]struct{}) ([]gitdiff.TaskChange, []gitdiff.Blocker, string, error) {
 	system := readPromptFile("task_chat.txt")
 	if system == "" {
-		return currentTasks, "System error: prompt file task_chat.txt not found", fmt.Errorf("prompt file task_chat.txt not found")
+		return currentTasks, currentBlockers, "System error: prompt file task_chat.txt not found", fmt.Errorf("prompt file task_chat.txt not found")
 	}
 
 	tasksJSON, _ := json.MarshalIndent(currentTasks, "", "  ")
 	system = strings.Replace(system, "{{TASKS_JSON}}", string(tasksJSON), 1)
+	blockersJSON, _ := json.MarshalIndent(currentBlockers, "", "  ")
+	system = strings.Replace(system, "{{BLOCKERS_JSON}}", string(blockersJSON), 1)
 
 	// Create Task tools
-	taskTools := tools.NewTaskTools(currentTasks)
+	taskTools := tools.NewTaskTools(currentTasks, currentBlockers, date)
 	agent := NewAgent(options, taskTools)
 
 	responseText, toolUsed, err := agent.StreamChat(history, system)
 	if err != nil {
-		return currentTasks, "", err
+		return currentTasks, currentBlockers, "", err
 	}
 	if toolUsed {
-		return taskTools.GetUpdatedTasks(), responseText, nil
+		return taskTools.GetUpdatedTasks(), taskTools.GetUpdatedBlockers(), responseText, nil
 	}
 
 	parsedTools := parseToolCallsFromText(responseText)
@@ -40,7 +44,7 @@ func StreamChatWithRequests(history []OpenAIMessage, currentTasks []gitdiff.Task
 	if len(parsedTools) == 0 {
 		forcedTools, forcedText, err := agent.ForceToolCalls(history, system)
 		if err != nil {
-			return taskTools.GetUpdatedTasks(), responseText, nil
+			return taskTools.GetUpdatedTasks(), taskTools.GetUpdatedBlockers(), responseText, nil
 		}
 		log.Printf("[llm.StreamChatWithRequests] forcedTools=%d forcedLen=%d forced=%q",
 			len(forcedTools),
@@ -49,10 +53,35 @@ func StreamChatWithRequests(history []OpenAIMessage, currentTasks []gitdiff.Task
 		)
 		parsedTools = forcedTools
 		if len(parsedTools) == 0 {
-			return taskTools.GetUpdatedTasks(), responseText, nil
+			return taskTools.GetUpdatedTasks(), taskTools.GetUpdatedBlockers(), responseText, nil
 		}
 	}
 
+	// Blocker tools are not part of ApplyTools; run them directly against the shared list
+	var taskCalls []ToolCall
+	for _, tool := range parsedTools {
+		if tool.Tool != "set_blocker" && tool.Tool != "clear_blocker" {
+			taskCalls = append(taskCalls, tool)
+			continue
+		}
+		t, _ := taskTools.Find(tool.Tool)
+		paramsJSON, _ := json.Marshal(tool.Parameters)
+		if options.OnToolStart != nil {
+			options.OnToolStart(tool.Tool, string(paramsJSON))
+		}
+		result, err := t.Call(context.Background(), string(paramsJSON))
+		if err != nil {
+			result = fmt.Sprintf("Error executing tool: %v", err)
+		}
+		if options.OnToolEnd != nil {
+			options.OnToolEnd(tool.Tool, result)
+		}
+	}
+	parsedTools = taskCalls
+	if len(parsedTools) == 0 {
+		return currentTasks, taskTools.GetUpdatedBlockers(), responseText, nil
+	}
+
 	if options.OnToolStart != nil {
 		for _, tool := range parsedTools {
 			paramsJSON, _ := json.Marshal(tool.Parameters)
@@ -60,7 +89,7 @@ func StreamChatWithRequests(history []OpenAIMessage, currentTasks []gitdiff.Task
 		}
 	}
 
-	updatedTasks, log, status := ApplyTools(parsedTools, currentTasks, allowedCommits)
+	updatedTasks, log, status := applyTools(parsedTools, currentTasks, allowedCommits, options.Privacy)
 
 	if options.OnToolEnd != nil {
 		resultData := map[string]interface{}{
@@ -80,7 +109,7 @@ func StreamChatWithRequests(history []OpenAIMessage, currentTasks []gitdiff.Task
 		responseText = fmt.Sprintf("%s\n\n(Action: %s)", responseText, status)
 	}
 
-	return updatedTasks, responseText, nil
+	return updatedTasks, taskTools.GetUpdatedBlockers(), responseText, nil
 }
 
 func truncateForLog(s string, max int) string {
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"md2slack/internal/gitdiff"
+)
+
+// ClearBlockerTool implements the tools.Tool interface for resolving blockers
+type ClearBlockerTool struct {
+	Blockers *[]gitdiff.Blocker
+	Date     string
+}
+
+func (t *ClearBlockerTool) Name() string {
+	return "clear_blocker"
+}
+
+func (t *ClearBlockerTool) Description() string {
+	return `Marks a blocker as resolved so it is no longer carried forward.
+Parameters (JSON):
+{
+  "index": 0  // index of the blocker to resolve
+}`
+}
+
+func (t *ClearBlockerTool) Call(ctx context.Context, input string) (string, error) {
+	var params struct {
+		Index int `json:"index"`
+	}
+
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return "", fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	if params.Index < 0 || params.Index >= len(*t.Blockers) {
+		return "", fmt.Errorf("blocker index %d out of bounds (0-%d)", params.Index, len(*t.Blockers)-1)
+	}
+
+	blocker := &(*t.Blockers)[params.Index]
+	blocker.ResolvedOn = gitdiff.ISODate(t.Date)
+
+	resultJSON, _ := json.Marshal(map[string]interface{}{
+		"status":  "resolved",
+		"index":   params.Index,
+		"blocker": blocker,
+	})
+	return string(resultJSON), nil
+}
@@ -58,5 +58,35 @@ func GetLLMDefinitions() []llms.Tool {
 				},
 			},
 		},
+		{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name:        "set_blocker",
+				Description: "Record a blocker for the report, or update an open one when index is given.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"description": map[string]interface{}{"type": "string", "description": "What is blocking the work"},
+						"owner":       map[string]interface{}{"type": "string", "description": "Who can unblock it"},
+						"index":       map[string]interface{}{"type": "integer", "description": "Index of an existing blocker to update"},
+					},
+					"required": []string{"description"},
+				},
+			},
+		},
+		{
+			Type: "function",
+			Function: &llms.FunctionDefinition{
+				Name:        "clear_blocker",
+				Description: "Mark a blocker as resolved so it is no longer carried forward.",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"index": map[string]interface{}{"type": "integer", "description": "Index of the blocker to resolve"},
+					},
+					"required": []string{"index"},
+				},
+			},
+		},
 	}
 }
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"md2slack/internal/gitdiff"
+	"strings"
+)
+
+// SetBlockerTool implements the tools.Tool interface for recording blockers
+type SetBlockerTool struct {
+	Blockers *[]gitdiff.Blocker
+	Date     string
+}
+
+func (t *SetBlockerTool) Name() string {
+	return "set_blocker"
+}
+
+func (t *SetBlockerTool) Description() string {
+	return `Records a blocker for the report, or updates an open one when index is given.
+Parameters (JSON):
+{
+  "description": "string - what is blocking the work",
+  "owner": "string - who can unblock it (optional)",
+  "index": 0  // index of an existing blocker to update (optional)
+}`
+}
+
+func (t *SetBlockerTool) Call(ctx context.Context, input string) (string, error) {
+	var params struct {
+		Description string `json:"description"`
+		Owner       string `json:"owner"`
+		Index       *int   `json:"index,omitempty"`
+	}
+
+	if err := json.Unmarshal([]byte(input), &params); err != nil {
+		return "", fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	description := strings.TrimSpace(params.Description)
+	owner := strings.TrimPrefix(strings.TrimSpace(params.Owner), "@")
+
+	if params.Index != nil {
+		idx := *params.Index
+		if idx < 0 || idx >= len(*t.Blockers) {
+			return "", fmt.Errorf("blocker index %d out of bounds (0-%d)", idx, len(*t.Blockers)-1)
+		}
+		blocker := &(*t.Blockers)[idx]
+		if description != "" {
+			blocker.Description = description
+		}
+		if owner != "" {
+			blocker.Owner = owner
+		}
+		resultJSON, _ := json.Marshal(map[string]interface{}{
+			"status":  "updated",
+			"index":   idx,
+			"blocker": blocker,
+		})
+		return string(resultJSON), nil
+	}
+
+	if description == "" {
+		return "", fmt.Errorf("description is required")
+	}
+
+	blocker := gitdiff.Blocker{
+		Description: description,
+		Owner:       owner,
+		OpenedOn:    gitdiff.ISODate(t.Date),
+	}
+	*t.Blockers = append(*t.Blockers, blocker)
+
+	resultJSON, _ := json.Marshal(map[string]interface{}{
+		"status":  "created",
+		"index":   len(*t.Blockers) - 1,
+		"blocker": blocker,
+	})
+	return string(resultJSON), nil
+}
@@ -11,19 +11,31 @@ type TaskTools struct {
 	CreateTask *CreateTaskTool
 	UpdateTask *UpdateTaskTool
 	DeleteTask *DeleteTaskTool
+
+	SetBlocker   *SetBlockerTool
+	ClearBlocker *ClearBlockerTool
+	blockers     *[]gitdiff.Blocker
 	// TODO: Add SplitTask, MergeTasks, SearchCodebase, etc.
 }
 
 // NewTaskTools creates a new set of task manipulation tools initialized with the current tasks
-func NewTaskTools(currentTasks []gitdiff.TaskChange) *TaskTools {
+// and the blockers open on date
+func NewTaskTools(currentTasks []gitdiff.TaskChange, currentBlockers []gitdiff.Blocker, date string) *TaskTools {
 	// Make a copy of tasks for each tool to work with
 	tasksCopy := make([]gitdiff.TaskChange, len(currentTasks))
 	copy(tasksCopy, currentTasks)
 
+	// Blocker tools share a single list so resolutions and additions see each other
+	blockersCopy := make([]gitdiff.Blocker, len(currentBlockers))
+	copy(blockersCopy, currentBlockers)
+
 	return &TaskTools{
-		CreateTask: &CreateTaskTool{Tasks: tasksCopy},
-		UpdateTask: &UpdateTaskTool{Tasks: tasksCopy},
-		DeleteTask: &DeleteTaskTool{Tasks: tasksCopy},
+		CreateTask:   &CreateTaskTool{Tasks: tasksCopy},
+		UpdateTask:   &UpdateTaskTool{Tasks: tasksCopy},
+		DeleteTask:   &DeleteTaskTool{Tasks: tasksCopy},
+		SetBlocker:   &SetBlockerTool{Blockers: &blockersCopy, Date: date},
+		ClearBlocker: &ClearBlockerTool{Blockers: &blockersCopy, Date: date},
+		blockers:     &blockersCopy,
 	}
 }
 
@@ -33,6 +45,8 @@ func (tt *TaskTools) AsList() []tools.Tool {
 		tt.CreateTask,
 		tt.UpdateTask,
 		tt.DeleteTask,
+		tt.SetBlocker,
+		tt.ClearBlocker,
 	}
 }
 
@@ -43,6 +57,11 @@ func (tt *TaskTools) GetUpdatedTasks() []gitdiff.TaskChange {
 	return tt.CreateTask.GetUpdatedTasks()
 }
 
+// GetUpdatedBlockers returns the blockers after all modifications, including resolved ones
+func (tt *TaskTools) GetUpdatedBlockers() []gitdiff.Blocker {
+	return *tt.blockers
+}
+
 // Find returns a tool by name
 func (tt *TaskTools) Find(name string) (tools.Tool, bool) {
 	switch name {
@@ -52,6 +71,10 @@ func (tt *TaskTools) Find(name string) (tools.Tool, bool) {
 		return tt.UpdateTask, true
 	case "delete_task":
 		return tt.DeleteTask, true
+	case "set_blocker":
+		return tt.SetBlocker, true
+	case "clear_blocker":
+		return tt.ClearBlocker, true
 	default:
 		return nil, false
 	}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+
+	"md2slack/internal/config"
+	"md2slack/internal/discord"
+	"md2slack/internal/email"
+	"md2slack/internal/slack"
+	"md2slack/internal/teams"
+)
+
+// Notifier delivers a rendered markdown report to one destination.
+type Notifier interface {
+	Name() string
+	Send(subject string, markdown string) error
+}
+
+// Configured returns the notifiers that have enough configuration to send
+// the report for date, keyed by name. Email recipients are resolved for
+// repoName.
+func Configured(cfg *config.Config, repoName string, date string) map[string]Notifier {
+	all := []Notifier{}
+	if cfg.Slack.BotToken != "" && cfg.Slack.ChannelID != "" {
+		all = append(all, &slack.Notifier{Config: &cfg.Slack})
+	}
+	if cfg.Email.Host != "" && len(cfg.Email.RecipientsFor(repoName)) > 0 {
+		all = append(all, &email.Notifier{Config: &cfg.Email, To: cfg.Email.RecipientsFor(repoName), Date: date})
+	}
+	if cfg.Teams.WebhookURL != "" {
+		all = append(all, &teams.Notifier{WebhookURL: cfg.Teams.WebhookURL})
+	}
+	if cfg.Discord.WebhookURL != "" {
+		all = append(all, &discord.Notifier{WebhookURL: cfg.Discord.WebhookURL})
+	}
+
+	out := make(map[string]Notifier, len(all))
+	for _, n := range all {
+		out[n.Name()] = n
+	}
+	return out
+}
+
+// Names returns the sorted notifier names.
+func Names(notifiers map[string]Notifier) []string {
+	names := make([]string, 0, len(notifiers))
+	for name := range notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Send delivers the report through the named notifier.
+func Send(notifiers map[string]Notifier, name string, subject string, markdown string) error {
+	n, ok := notifiers[name]
+	if !ok {
+		return fmt.Errorf("destination %q is not configured", name)
+	}
+	return n.Send(subject, markdown)
+}
@@ -0,0 +1,187 @@
+// Package outline finds the functions, methods, classes, React components,
+// types and HTTP routes in TypeScript, JavaScript and Python source, and
+// compares two versions of a file by those symbols. It scans the source
+// instead of fully parsing it, so it never fails; symbols it cannot make
+// sense of are simply missing from the outline.
+package outline
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Symbol kinds.
+const (
+	KindFunction  = "function"
+	KindMethod    = "method"
+	KindClass     = "class"
+	KindComponent = "component"
+	KindType      = "type"
+	KindRoute     = "route"
+)
+
+// Change kinds. They double as signal types in gitdiff.
+const (
+	FuncAdded       = "func_added"
+	FuncRemoved     = "func_removed"
+	FuncChanged     = "func_changed"
+	TypeAdded       = "type_added"
+	TypeChange      = "type_change"
+	ComponentChange = "component_change"
+	RouteChange     = "route_change"
+)
+
+// Symbol is a named region of a file.
+type Symbol struct {
+	Kind    string
+	Name    string // "Class.method" for methods, "POST /orders" for routes
+	Handler string // for routes, the function handling it, if named
+	Start   int    // first line, 1-based
+	End     int    // last line
+}
+
+// Change is one symbol-level difference between two versions.
+type Change struct {
+	Kind string
+	Name string
+	Hint string
+}
+
+// Supports reports whether the file is a language this package outlines.
+func Supports(file string) bool {
+	return language(file) != ""
+}
+
+func language(file string) string {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts":
+		return "js"
+	case ".py":
+		return "python"
+	}
+	return ""
+}
+
+// Symbols returns the outline of a file, in source order.
+func Symbols(file string, src string) []Symbol {
+	lines := strings.Split(src, "\n")
+	switch language(file) {
+	case "js":
+		return scriptSymbols(file, lines)
+	case "python":
+		return pythonSymbols(lines)
+	}
+	return nil
+}
+
+// Compare outlines before and after and reports the symbols that were
+// added, removed or whose text changed, in the order they appear. A class
+// is only reported as changed when none of its methods are.
+func Compare(file string, before, after string) []Change {
+	old := index(Symbols(file, before), before)
+	cur := index(Symbols(file, after), after)
+
+	var changes []Change
+	changedClasses := make(map[string]bool)
+	report := func(verb string, s Symbol) {
+		kind := changeKind(s.Kind, verb)
+		label := s.Kind
+		if s.Kind == KindRoute {
+			label = "handler"
+		}
+		hint := fmt.Sprintf("%s %s %s", verb, label, s.Name)
+		if s.Handler != "" {
+			hint += " (" + s.Handler + ")"
+		}
+		changes = append(changes, Change{Kind: kind, Name: s.Name, Hint: hint})
+	}
+
+	for _, key := range cur.order {
+		c := cur.symbols[key]
+		o, ok := old.symbols[key]
+		switch {
+		case !ok:
+			report("added", c.Symbol)
+		case o.text != c.text && c.Kind != KindClass:
+			report("changed", c.Symbol)
+			if c.Kind == KindMethod {
+				class, _, _ := strings.Cut(c.Name, ".")
+				changedClasses[class] = true
+			}
+		}
+	}
+	for _, key := range cur.order {
+		c := cur.symbols[key]
+		if o, ok := old.symbols[key]; ok && c.Kind == KindClass && o.text != c.text && !changedClasses[c.Name] {
+			report("changed", c.Symbol)
+		}
+	}
+	for _, key := range old.order {
+		if _, ok := cur.symbols[key]; !ok {
+			report("removed", old.symbols[key].Symbol)
+		}
+	}
+	return changes
+}
+
+func changeKind(symbolKind string, verb string) string {
+	switch symbolKind {
+	case KindRoute:
+		return RouteChange
+	case KindComponent:
+		return ComponentChange
+	case KindClass, KindType:
+		if verb == "added" {
+			return TypeAdded
+		}
+		return TypeChange
+	}
+	switch verb {
+	case "added":
+		return FuncAdded
+	case "removed":
+		return FuncRemoved
+	}
+	return FuncChanged
+}
+
+type symbolText struct {
+	Symbol
+	text string
+}
+
+type outlineIndex struct {
+	symbols map[string]symbolText
+	order   []string
+}
+
+// index keys symbols by kind and name, numbering repeats, and keeps their
+// text with indentation and blank lines removed so reformatting does not
+// count as a change.
+func index(symbols []Symbol, src string) outlineIndex {
+	lines := strings.Split(src, "\n")
+	idx := outlineIndex{symbols: make(map[string]symbolText)}
+	seen := make(map[string]int)
+	for _, s := range symbols {
+		key := s.Kind + " " + s.Name
+		if seen[key]++; seen[key] > 1 {
+			key = fmt.Sprintf("%s #%d", key, seen[key])
+		}
+		var text []string
+		for i := s.Start - 1; i < s.End && i < len(lines); i++ {
+			if l := strings.TrimSpace(lines[i]); l != "" {
+				text = append(text, l)
+			}
+		}
+		idx.symbols[key] = symbolText{Symbol: s, text: strings.Join(text, "\n")}
+		idx.order = append(idx.order, key)
+	}
+	return idx
+}
+
+func sortSymbols(symbols []Symbol) []Symbol {
+	sort.SliceStable(symbols, func(i, j int) bool { return symbols[i].Start < symbols[j].Start })
+	return symbols
+}
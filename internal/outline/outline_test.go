@@ -0,0 +1,246 @@
+package outline
+
+import (
+	"reflect"
+	"testing"
+)
+
+func names(symbols []Symbol) []string {
+	var out []string
+	for _, s := range symbols {
+		out = append(out, s.Kind+" "+s.Name)
+	}
+	return out
+}
+
+func hints(changes []Change) []string {
+	var out []string
+	for _, c := range changes {
+		out = append(out, c.Hint)
+	}
+	return out
+}
+
+const ordersBefore = `import express from "express";
+
+const router = express.Router();
+
+// Lists orders; the "{" in this comment is ignored
+router.get("/orders", async (req, res) => {
+  res.json(await db.orders.list());
+});
+
+router.post("/orders", async (req, res) => {
+  const order = await db.orders.create(req.body);
+  res.status(201).json(order);
+});
+
+export class OrderService {
+  private readonly db: Db;
+
+  constructor(db: Db) {
+    this.db = db;
+  }
+
+  async total(id: string): Promise<number> {
+    return 0;
+  }
+}
+
+export interface Order {
+  id: string;
+}
+
+function legacy() {}
+`
+
+const ordersAfter = `import express from "express";
+
+const router = express.Router();
+
+// Lists orders; the "{" in this comment is ignored
+router.get("/orders", async (req, res) => {
+  res.json(await db.orders.list());
+});
+
+router.post("/orders", async (req, res) => {
+  if (!req.body.items?.length) {
+    return res.status(400).json({ error: "empty order" });
+  }
+  const order = await db.orders.create(req.body);
+  res.status(201).json(order);
+});
+
+export class OrderService {
+  private readonly db: Db;
+
+  constructor(db: Db) {
+    this.db = db;
+  }
+
+  async total(id: string): Promise<number> {
+    const order = await this.db.orders.get(id);
+    return order.items.reduce((sum, i) => sum + i.price, 0);
+  }
+}
+
+export interface Order {
+  id: string;
+  items: Item[];
+}
+
+export const formatTotal = (n: number) => ` + "`$${n.toFixed(2)}`" + `;
+`
+
+func TestScriptSymbols(t *testing.T) {
+	got := Symbols("src/routes/orders.ts", ordersAfter)
+	want := []string{
+		"route GET /orders",
+		"route POST /orders",
+		"class OrderService",
+		"method OrderService.constructor",
+		"method OrderService.total",
+		"type Order",
+		"function formatTotal",
+	}
+	if !reflect.DeepEqual(names(got), want) {
+		t.Fatalf("unexpected symbols: %q", names(got))
+	}
+	if post := got[1]; post.Start != 10 || post.End != 16 {
+		t.Fatalf("unexpected POST /orders range: %d-%d", post.Start, post.End)
+	}
+}
+
+func TestCompareScript(t *testing.T) {
+	got := hints(Compare("src/routes/orders.ts", ordersBefore, ordersAfter))
+	want := []string{
+		"changed handler POST /orders",
+		"changed method OrderService.total",
+		"changed type Order",
+		"added function formatTotal",
+		"removed function legacy",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected changes: %q", got)
+	}
+}
+
+func TestScriptComponentsAndNextRoutes(t *testing.T) {
+	src := `"use client";
+
+export default function CheckoutPage() {
+  return <p>Don't leave {"yet"}</p>;
+}
+
+const Summary = memo(({ total }) => <b>{total}</b>);
+
+export function useCart() {
+  return useContext(CartContext);
+}
+`
+	if got := names(Symbols("app/(shop)/checkout/page.tsx", src)); !reflect.DeepEqual(got, []string{"component CheckoutPage", "component Summary", "function useCart"}) {
+		t.Fatalf("unexpected symbols: %q", got)
+	}
+
+	route := "export async function POST(req: Request) {\n  return Response.json({ ok: true });\n}\n"
+	if got := names(Symbols("app/(shop)/api/orders/route.ts", route)); !reflect.DeepEqual(got, []string{"route POST /api/orders"}) {
+		t.Fatalf("unexpected app route: %q", got)
+	}
+	pages := "export default async function handler(req, res) {\n  res.end();\n}\n"
+	got := Symbols("pages/api/orders/index.js", pages)
+	if len(got) != 1 || got[0].Name != "/api/orders" || got[0].Handler != "handler" {
+		t.Fatalf("unexpected pages route: %+v", got)
+	}
+}
+
+const viewsBefore = `from flask import Flask
+
+app = Flask(__name__)
+
+
+@app.route("/orders", methods=["GET", "POST"])
+def orders():
+    return list_orders()
+
+
+class Cart:
+    """A cart.
+
+    def not_a_method(self): ...
+    """
+
+    def total(self):
+        return sum(
+            i.price
+            for i in self.items
+        )
+
+    def clear(self):
+        def helper():
+            pass
+        self.items = []
+`
+
+const viewsAfter = `from flask import Flask
+
+app = Flask(__name__)
+
+
+@app.route("/orders", methods=["GET", "POST"])
+def orders():
+    return list_orders()
+
+
+@app.delete("/orders/<id>")
+def delete_order(id):
+    return remove(id)
+
+
+class Cart:
+    """A cart.
+
+    def not_a_method(self): ...
+    """
+
+    def total(self):
+        return sum(
+            i.price * i.qty
+            for i in self.items
+        )
+
+    def clear(self):
+        def helper():
+            pass
+        self.items = []
+`
+
+func TestPythonSymbols(t *testing.T) {
+	got := Symbols("app/views.py", viewsAfter)
+	want := []string{
+		"route GET /orders",
+		"route POST /orders",
+		"route DELETE /orders/<id>",
+		"class Cart",
+		"method Cart.total",
+		"method Cart.clear",
+	}
+	if !reflect.DeepEqual(names(got), want) {
+		t.Fatalf("unexpected symbols: %q", names(got))
+	}
+	if total := got[4]; total.Start != 22 || total.End != 26 {
+		t.Fatalf("unexpected Cart.total range: %d-%d", total.Start, total.End)
+	}
+
+	changes := hints(Compare("app/views.py", viewsBefore, viewsAfter))
+	if !reflect.DeepEqual(changes, []string{"added handler DELETE /orders/<id> (delete_order)", "changed method Cart.total"}) {
+		t.Fatalf("unexpected changes: %q", changes)
+	}
+}
+
+func TestSupports(t *testing.T) {
+	for file, want := range map[string]bool{"a.tsx": true, "b.mjs": true, "c.py": true, "d.go": false, "e.rb": false} {
+		if Supports(file) != want {
+			t.Errorf("Supports(%q) = %v", file, !want)
+		}
+	}
+}
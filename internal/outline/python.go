@@ -0,0 +1,183 @@
+package outline
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	pyDefRe       = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+([A-Za-z_]\w*)`)
+	pyClassRe     = regexp.MustCompile(`^(\s*)class\s+([A-Za-z_]\w*)`)
+	pyDecoratorRe = regexp.MustCompile(`^\s*@`)
+	pyRouteRe     = regexp.MustCompile(`^\s*@\w+\.(get|post|put|patch|delete|route|api_route)\(\s*['"]([^'"]*)['"](.*)`)
+	pyMethodsRe   = regexp.MustCompile(`methods\s*=\s*[\[(]([^\])]*)`)
+)
+
+// pythonSymbols outlines Python by indentation: top-level functions and
+// classes, and the methods of top-level classes. Functions decorated with
+// a Flask, FastAPI or similar route (@app.post("/orders")) are routes. A
+// symbol starts at its first decorator and ends before the next line that
+// is indented no deeper than its def or class.
+func pythonSymbols(lines []string) []Symbol {
+	code := pythonCodeLines(lines)
+	var symbols []Symbol
+	class, classIndent := "", -1
+	decoratorStart := -1
+	var routes []string
+
+	for i, line := range lines {
+		if !code[i] {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if class != "" && indent <= classIndent {
+			class, classIndent = "", -1
+		}
+		if pyDecoratorRe.MatchString(line) {
+			if decoratorStart < 0 {
+				decoratorStart = i
+			}
+			if m := pyRouteRe.FindStringSubmatch(line); m != nil {
+				routes = append(routes, pythonRouteNames(m[1], m[2], m[3])...)
+			}
+			continue
+		}
+		start := i
+		if decoratorStart >= 0 {
+			start = decoratorStart
+		}
+		decorated := routes
+		decoratorStart, routes = -1, nil
+
+		if m := pyClassRe.FindStringSubmatch(line); m != nil && indent == 0 {
+			symbols = append(symbols, Symbol{Kind: KindClass, Name: m[2], Start: start + 1, End: pythonBlockEnd(lines, code, i, indent) + 1})
+			class, classIndent = m[2], indent
+			continue
+		}
+		m := pyDefRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		end := pythonBlockEnd(lines, code, i, indent) + 1
+		switch {
+		case len(decorated) > 0:
+			for _, r := range decorated {
+				symbols = append(symbols, Symbol{Kind: KindRoute, Name: r, Handler: m[2], Start: start + 1, End: end})
+			}
+		case indent == 0:
+			symbols = append(symbols, Symbol{Kind: KindFunction, Name: m[2], Start: start + 1, End: end})
+		case class != "" && indent > classIndent && isDirectMember(lines, code, i, classIndent):
+			symbols = append(symbols, Symbol{Kind: KindMethod, Name: class + "." + m[2], Start: start + 1, End: end})
+		}
+	}
+	return sortSymbols(symbols)
+}
+
+// pythonRouteNames names the routes a decorator registers: "POST /orders"
+// for @app.post("/orders"), and one name per method for Flask's
+// @app.route("/orders", methods=["GET", "POST"]), which defaults to GET.
+func pythonRouteNames(verb string, route string, rest string) []string {
+	if verb != "route" && verb != "api_route" {
+		return []string{strings.ToUpper(verb) + " " + route}
+	}
+	methods := []string{"GET"}
+	if m := pyMethodsRe.FindStringSubmatch(rest); m != nil {
+		methods = nil
+		for _, part := range strings.Split(m[1], ",") {
+			if part = strings.Trim(strings.TrimSpace(part), `'"`); part != "" {
+				methods = append(methods, strings.ToUpper(part))
+			}
+		}
+	}
+	var names []string
+	for _, method := range methods {
+		names = append(names, method+" "+route)
+	}
+	return names
+}
+
+// isDirectMember reports whether the def on line i belongs to the class
+// body rather than to a function nested in it: no code line between the
+// class and the def is indented less than the def but more than the class.
+func isDirectMember(lines []string, code []bool, i int, classIndent int) bool {
+	indent := len(lines[i]) - len(strings.TrimLeft(lines[i], " \t"))
+	for j := i - 1; j >= 0; j-- {
+		if !code[j] {
+			continue
+		}
+		other := len(lines[j]) - len(strings.TrimLeft(lines[j], " \t"))
+		if other <= classIndent {
+			return true
+		}
+		if other < indent {
+			return false
+		}
+	}
+	return true
+}
+
+// pythonBlockEnd returns the last line of the block opened on line i: the
+// last non-blank line indented deeper than it before the next statement
+// that is not.
+func pythonBlockEnd(lines []string, code []bool, i int, indent int) int {
+	end := i
+	for j := i + 1; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		deeper := len(lines[j])-len(strings.TrimLeft(lines[j], " \t")) > indent
+		if code[j] && !deeper {
+			break
+		}
+		if deeper {
+			end = j
+		}
+	}
+	return end
+}
+
+// pythonCodeLines marks the lines that can start a statement: not blank,
+// not comments, and not inside a triple-quoted string. Lines inside
+// brackets opened on an earlier line are continuations and not marked
+// either, so a long call does not end a block early.
+func pythonCodeLines(lines []string) []bool {
+	code := make([]bool, len(lines))
+	triple := ""
+	depth := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		startsInString := triple != ""
+		code[i] = !startsInString && depth == 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#")
+		var quote byte
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			switch {
+			case triple != "":
+				if strings.HasPrefix(line[j:], triple) {
+					j += 2
+					triple = ""
+				}
+			case quote != 0:
+				if c == '\\' {
+					j++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '#':
+				j = len(line)
+			case strings.HasPrefix(line[j:], `"""`) || strings.HasPrefix(line[j:], `'''`):
+				triple = line[j : j+3]
+				j += 2
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '(' || c == '[' || c == '{':
+				depth++
+			case c == ')' || c == ']' || c == '}':
+				if depth > 0 {
+					depth--
+				}
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,197 @@
+package outline
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	jsClassRe      = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+([A-Za-z_$][\w$]*)`)
+	jsFuncRe       = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)`)
+	jsArrowRe      = regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|\(|[A-Za-z_$][\w$]*\s*=>|(?:React\.)?(memo|forwardRef)\s*\()`)
+	jsTypeRe       = regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?(?:(?:interface|enum)\s+([A-Za-z_$][\w$]*)|type\s+([A-Za-z_$][\w$]*)\b[^=]*=)`)
+	jsMethodRe     = regexp.MustCompile(`^\s*(?:(?:public|private|protected|static|async|readonly|override|get|set)\s+)*\*?#?([A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\s*(?:\(|=\s*(?:async\s+)?\()`)
+	jsRouteRe      = regexp.MustCompile(`\b[A-Za-z_$][\w$]*\.(get|post|put|patch|delete|all)\s*\(\s*['"` + "`" + `](/[^'"` + "`" + `]*)`)
+	nextAppRouteRe = regexp.MustCompile(`(?:^|/)app/(.*?)/?route\.[cm]?[jt]sx?$`)
+	nextPagesAPIRe = regexp.MustCompile(`(?:^|/)pages/(api/.*?)(?:/index)?\.[cm]?[jt]sx?$`)
+)
+
+var jsKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"with": true, "return": true, "super": true, "new": true, "function": true,
+}
+
+var httpMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true, "OPTIONS": true,
+}
+
+// scriptSymbols outlines JavaScript and TypeScript. Symbols end where the
+// brackets opened on their first line close. Top-level functions, classes
+// and types are outlined, plus the methods of top-level classes and route
+// registrations such as router.post("/orders", ...) at any depth. In Next.js
+// route files, exported GET/POST/... functions are routes.
+func scriptSymbols(file string, lines []string) []Symbol {
+	depths := bracketDepths(lines)
+	ext := strings.ToLower(path.Ext(file))
+	jsx := ext == ".jsx" || ext == ".tsx"
+
+	appRoute := ""
+	if m := nextAppRouteRe.FindStringSubmatch(file); m != nil {
+		appRoute = "/" + routeSegments(m[1])
+	}
+	pagesRoute := ""
+	if m := nextPagesAPIRe.FindStringSubmatch(file); m != nil {
+		pagesRoute = "/" + routeSegments(m[1])
+	}
+
+	var symbols []Symbol
+	class, classDepth, classEnd := "", -1, -1
+	for i, line := range lines {
+		d := depths[i]
+		if i > classEnd {
+			class, classDepth = "", -1
+		}
+		add := func(kind, name, handler string) {
+			symbols = append(symbols, Symbol{Kind: kind, Name: name, Handler: handler, Start: i + 1, End: symbolEnd(depths, i) + 1})
+		}
+
+		if m := jsRouteRe.FindStringSubmatch(line); m != nil {
+			add(KindRoute, strings.ToUpper(m[1])+" "+m[2], "")
+			continue
+		}
+		if d.start == 0 {
+			if m := jsClassRe.FindStringSubmatch(line); m != nil {
+				add(KindClass, m[1], "")
+				class, classDepth, classEnd = m[1], 1, symbolEnd(depths, i)
+				continue
+			}
+			if m := jsTypeRe.FindStringSubmatch(line); m != nil {
+				add(KindType, m[1]+m[2], "")
+				continue
+			}
+			name, wrapped := "", false
+			if m := jsFuncRe.FindStringSubmatch(line); m != nil {
+				name = m[1]
+			} else if m := jsArrowRe.FindStringSubmatch(line); m != nil {
+				name, wrapped = m[1], m[2] != ""
+				if !wrapped && !strings.Contains(line, "=>") && !strings.Contains(line, "function") {
+					name = "" // a parenthesized expression, not a function
+				}
+			}
+			if name == "" {
+				continue
+			}
+			switch {
+			case appRoute != "" && httpMethods[name] && strings.Contains(line, "export"):
+				add(KindRoute, name+" "+appRoute, "")
+			case pagesRoute != "" && strings.Contains(line, "export default"):
+				add(KindRoute, pagesRoute, name)
+			case wrapped || (jsx && isCapitalized(name)):
+				add(KindComponent, name, "")
+			default:
+				add(KindFunction, name, "")
+			}
+			continue
+		}
+		if class != "" && d.start == classDepth {
+			if m := jsMethodRe.FindStringSubmatch(line); m != nil && !jsKeywords[m[1]] {
+				add(KindMethod, class+"."+m[1], "")
+			}
+		}
+	}
+	return sortSymbols(symbols)
+}
+
+// routeSegments turns a Next.js route directory into a URL path, dropping
+// route groups like "(shop)".
+func routeSegments(dir string) string {
+	var parts []string
+	for _, p := range strings.Split(dir, "/") {
+		if p == "" || (strings.HasPrefix(p, "(") && strings.HasSuffix(p, ")")) {
+			continue
+		}
+		parts = append(parts, p)
+	}
+	return strings.Join(parts, "/")
+}
+
+func isCapitalized(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
+
+type lineDepth struct {
+	start int // bracket depth at the start of the line
+	max   int // deepest point within the line
+	end   int // depth after the line
+}
+
+// bracketDepths tracks (, [ and { nesting line by line, skipping comments,
+// strings and template literals. Quotes do not carry over to the next
+// line, so an apostrophe in JSX text only affects its own line.
+func bracketDepths(lines []string) []lineDepth {
+	out := make([]lineDepth, len(lines))
+	depth := 0
+	inBlock, inTemplate := false, false
+	for i, line := range lines {
+		out[i] = lineDepth{start: depth, max: depth}
+		var quote byte
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			switch {
+			case inBlock:
+				if c == '*' && j+1 < len(line) && line[j+1] == '/' {
+					inBlock = false
+					j++
+				}
+			case inTemplate:
+				if c == '\\' {
+					j++
+				} else if c == '`' {
+					inTemplate = false
+				}
+			case quote != 0:
+				if c == '\\' {
+					j++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '/' && j+1 < len(line) && line[j+1] == '/':
+				j = len(line)
+			case c == '/' && j+1 < len(line) && line[j+1] == '*':
+				inBlock = true
+				j++
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '`':
+				inTemplate = true
+			case c == '(' || c == '[' || c == '{':
+				depth++
+				if depth > out[i].max {
+					out[i].max = depth
+				}
+			case c == ')' || c == ']' || c == '}':
+				if depth > 0 {
+					depth--
+				}
+			}
+		}
+		out[i].end = depth
+	}
+	return out
+}
+
+// symbolEnd returns the line where the brackets opened on line i close
+// again. A symbol that opens none ends on its own line.
+func symbolEnd(depths []lineDepth, i int) int {
+	base := depths[i].start
+	if depths[i].max <= base {
+		return i
+	}
+	for j := i; j < len(depths); j++ {
+		if depths[j].end <= base {
+			return j
+		}
+	}
+	return len(depths) - 1
+}
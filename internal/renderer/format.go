@@ -0,0 +1,184 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+)
+
+// Format converts a rendered markdown report into another output format.
+// The report data is passed alongside for formats that do not start from
+// markdown, such as json.
+type Format struct {
+	Name        string
+	Extension   string
+	ContentType string
+	Render      func(markdown string, r Report) (string, error)
+}
+
+var formats = map[string]Format{}
+
+func init() {
+	RegisterFormat(Format{Name: "markdown", Extension: "md", ContentType: "text/markdown; charset=utf-8", Render: renderMarkdownFormat})
+	RegisterFormat(Format{Name: "html", Extension: "html", ContentType: "text/html; charset=utf-8", Render: renderHTMLFormat})
+	RegisterFormat(Format{Name: "json", Extension: "json", ContentType: "application/json", Render: renderJSONFormat})
+	RegisterFormat(Format{Name: "text", Extension: "txt", ContentType: "text/plain; charset=utf-8", Render: renderTextFormat})
+	RegisterFormat(Format{Name: "jira-wiki", Extension: "txt", ContentType: "text/plain; charset=utf-8", Render: renderJiraFormat})
+}
+
+// RegisterFormat adds or replaces an output format.
+func RegisterFormat(f Format) {
+	formats[strings.ToLower(f.Name)] = f
+}
+
+// LookupFormat returns the named format. An empty name means markdown.
+func LookupFormat(name string) (Format, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "md" {
+		name = "markdown"
+	}
+	f, ok := formats[name]
+	return f, ok
+}
+
+// FormatNames lists the registered formats.
+func FormatNames() []string {
+	var names []string
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderFormat converts the markdown report into the named format.
+func RenderFormat(name string, markdown string, r Report) (string, error) {
+	f, ok := LookupFormat(name)
+	if !ok {
+		return "", fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(FormatNames(), ", "))
+	}
+	return f.Render(markdown, r)
+}
+
+func renderMarkdownFormat(markdown string, _ Report) (string, error) {
+	return markdown, nil
+}
+
+// MarkdownToHTML converts markdown into an HTML fragment. Raw HTML and
+// javascript: links are dropped: reports carry model output and commit
+// text, which end up in emails and the web UI.
+func MarkdownToHTML(md string) (string, error) {
+	var buf bytes.Buffer
+	if err := goldmark.Convert([]byte(md), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderHTMLFormat produces a standalone document with inline styles so it
+// survives email clients that strip <style> blocks.
+func renderHTMLFormat(markdown string, r Report) (string, error) {
+	body, err := MarkdownToHTML(markdown)
+	if err != nil {
+		return "", err
+	}
+	title := "Daily Status Report"
+	if r.Date != "" {
+		title += " " + r.Date
+	}
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+</head>
+<body style="font-family: -apple-system, Segoe UI, Helvetica, Arial, sans-serif; font-size: 14px; line-height: 1.5; color: #1f2328;">
+%s</body>
+</html>
+`, html.EscapeString(title), body), nil
+}
+
+type jsonReport struct {
+	Report
+	Markdown string `json:"markdown"`
+}
+
+func renderJSONFormat(markdown string, r Report) (string, error) {
+	r.Blockers = r.OpenBlockers()
+	out, err := json.MarshalIndent(jsonReport{Report: r, Markdown: markdown}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
+var (
+	mdBoldRe    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	mdItalicRe  = regexp.MustCompile(`(^|[\s(])[_*]([^_*\s][^_*]*?)[_*]([\s).,:;!?]|$)`)
+	mdCodeRe    = regexp.MustCompile("`([^`]+)`")
+	mdLinkRe    = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	mdHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdBulletRe  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+)
+
+// renderTextFormat strips markdown markup, keeping bullets and indentation.
+func renderTextFormat(markdown string, _ Report) (string, error) {
+	var out []string
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+		if m := mdHeadingRe.FindStringSubmatch(line); m != nil {
+			line = m[2]
+		}
+		line = mdLinkRe.ReplaceAllString(line, "$1 ($2)")
+		line = mdBoldRe.ReplaceAllString(line, "$1")
+		line = mdItalicRe.ReplaceAllString(line, "$1$2$3")
+		line = mdCodeRe.ReplaceAllString(line, "$1")
+		out = append(out, strings.TrimRight(line, " "))
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// renderJiraFormat converts markdown into Jira wiki markup for issue comments.
+func renderJiraFormat(markdown string, _ Report) (string, error) {
+	var out []string
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			out = append(out, "{noformat}")
+			continue
+		}
+		if inFence {
+			out = append(out, line)
+			continue
+		}
+		if m := mdHeadingRe.FindStringSubmatch(line); m != nil {
+			line = fmt.Sprintf("h%d. %s", len(m[1]), jiraInline(m[2]))
+		} else if m := mdBulletRe.FindStringSubmatch(line); m != nil {
+			depth := len(strings.ReplaceAll(m[1], "\t", "  "))/2 + 1
+			line = strings.Repeat("*", depth) + " " + jiraInline(m[2])
+		} else {
+			line = jiraInline(line)
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+func jiraInline(line string) string {
+	line = mdCodeRe.ReplaceAllString(line, "{{$1}}")
+	line = mdLinkRe.ReplaceAllString(line, "[$1|$2]")
+	line = mdItalicRe.ReplaceAllString(line, "$1_$2_$3")
+	// Bold last: Jira uses single asterisks, which the italic pattern would
+	// otherwise pick up again.
+	line = mdBoldRe.ReplaceAllString(line, "*$1*")
+	return line
+}
@@ -0,0 +1,240 @@
+package renderer
+
+import (
+	"fmt"
+	"md2slack/internal/gitdiff"
+	"strings"
+	"text/template"
+)
+
+// Funcs returns the helper functions available to report templates.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"capitalize":  capitalize,
+		"hours":       taskHours,
+		"status":      statusKey,
+		"statusLabel": statusLabel,
+		"statusIcon":  statusIcon,
+		"details":     taskDetails,
+		"withStatus":  withStatus,
+		"join":        strings.Join,
+		"lower":       strings.ToLower,
+		"upper":       strings.ToUpper,
+		"trim":        strings.TrimSpace,
+		"indent":      indent,
+		"default":     defaultValue,
+		"ageDays":     func(b gitdiff.Blocker, date string) int { return b.AgeDays(date) },
+		"blockerMeta": blockerMeta,
+		"issues":      issueLinks,
+		"issueLink":   issueLink,
+		"issueDetail": issueDetail,
+		"untested":    untestedFiles,
+		"secret":      renderSecret,
+		"task":        renderTask,
+		"blocker":     renderBlocker,
+	}
+}
+
+func capitalize(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func taskHours(t gitdiff.TaskChange) int {
+	if t.EstimatedHours != nil && *t.EstimatedHours > 0 {
+		return *t.EstimatedHours
+	}
+	return 1
+}
+
+// statusKey normalizes task status to done, in_progress or on_hold.
+func statusKey(t gitdiff.TaskChange) string {
+	switch strings.ToLower(strings.TrimSpace(t.Status)) {
+	case "inprogress", "in_progress":
+		return "in_progress"
+	case "onhold", "on_hold":
+		return "on_hold"
+	default:
+		return "done"
+	}
+}
+
+func statusLabel(t gitdiff.TaskChange) string {
+	switch statusKey(t) {
+	case "in_progress":
+		return "In progress"
+	case "on_hold":
+		return "On hold"
+	default:
+		return "Done"
+	}
+}
+
+func statusIcon(t gitdiff.TaskChange) string {
+	switch statusKey(t) {
+	case "in_progress":
+		return "🕒"
+	case "on_hold":
+		return "⏸"
+	default:
+		return "✅"
+	}
+}
+
+func taskDetails(t gitdiff.TaskChange) []string {
+	var details []string
+	for _, line := range strings.Split(t.TechnicalWhy, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		details = append(details, line)
+	}
+	return details
+}
+
+// withStatus filters tasks by normalized status, e.g. withStatus .Tasks "done".
+func withStatus(tasks []gitdiff.TaskChange, statuses ...string) []gitdiff.TaskChange {
+	var out []gitdiff.TaskChange
+	for _, t := range tasks {
+		for _, s := range statuses {
+			if statusKey(t) == s {
+				out = append(out, t)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func defaultValue(def string, val interface{}) interface{} {
+	switch v := val.(type) {
+	case nil:
+		return def
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return def
+		}
+	case []string:
+		if len(v) == 0 {
+			return def
+		}
+	}
+	return val
+}
+
+func blockerMeta(b gitdiff.Blocker, date string) string {
+	var meta []string
+	if owner := strings.TrimPrefix(strings.TrimSpace(b.Owner), "@"); owner != "" {
+		meta = append(meta, "owner: @"+owner)
+	}
+	switch age := b.AgeDays(date); age {
+	case 0:
+		meta = append(meta, "since today")
+	case 1:
+		meta = append(meta, "open 1 day")
+	default:
+		meta = append(meta, fmt.Sprintf("open %d days", age))
+	}
+	return strings.Join(meta, ", ")
+}
+
+// issueLink renders an issue key as a markdown link, or as plain text when the
+// tracker has no URL template.
+func issueLink(ref gitdiff.IssueRef) string {
+	if ref.URL == "" {
+		return ref.Key
+	}
+	return fmt.Sprintf("[%s](%s)", ref.Key, ref.URL)
+}
+
+// issueLinks renders a task's issues as a comma separated list of links.
+func issueLinks(t gitdiff.TaskChange) string {
+	links := make([]string, len(t.Issues))
+	for i, ref := range t.Issues {
+		links[i] = issueLink(ref)
+	}
+	return strings.Join(links, ", ")
+}
+
+// issueDetail renders an issue link followed by its tracker title and status,
+// when enrichment provided them.
+func issueDetail(ref gitdiff.IssueRef) string {
+	out := issueLink(ref)
+	if ref.Title != "" {
+		out += " " + ref.Title
+	}
+	if ref.Status != "" {
+		out += fmt.Sprintf(" (%s)", ref.Status)
+	}
+	return out
+}
+
+// untestedFiles renders the source files a task changed without tests as a
+// comma separated list of code spans.
+func untestedFiles(t gitdiff.TaskChange) string {
+	if len(t.UntestedFiles) == 0 {
+		return ""
+	}
+	return "`" + strings.Join(t.UntestedFiles, "`, `") + "`"
+}
+
+// renderTask renders a task as a bullet with its details and commits, the
+// layout used by the default template.
+func renderTask(t gitdiff.TaskChange) string {
+	var details []string
+	for _, line := range taskDetails(t) {
+		details = append(details, fmt.Sprintf("  - %s", line))
+	}
+	var detailsStr string
+	if len(details) > 0 {
+		detailsStr = strings.Join(details, "\n") + "\n"
+	}
+
+	commitsLine := ""
+	if len(t.Commits) > 0 {
+		commitsLine = fmt.Sprintf("\n  - commits: `%s`", strings.Join(t.Commits, "`, `"))
+	}
+
+	issuesLine := ""
+	if len(t.Issues) > 0 {
+		details := make([]string, len(t.Issues))
+		for i, ref := range t.Issues {
+			details[i] = issueDetail(ref)
+		}
+		issuesLine = fmt.Sprintf("\n  - issues: %s", strings.Join(details, "; "))
+	}
+
+	untestedLine := ""
+	if len(t.UntestedFiles) > 0 {
+		untestedLine = "\n  - ⚠️ untested: " + untestedFiles(t)
+	}
+
+	return fmt.Sprintf("- %s — **%dh %s** %s\n%s%s%s%s",
+		capitalize(t.TaskIntent),
+		taskHours(t),
+		statusLabel(t),
+		statusIcon(t),
+		detailsStr,
+		commitsLine,
+		issuesLine,
+		untestedLine,
+	)
+}
+
+// renderSecret renders where a possible secret was committed, never the
+// secret itself.
+func renderSecret(f gitdiff.SecretFinding) string {
+	return fmt.Sprintf("- `%s` in `%s` (%s)", f.Where(), f.Commit, strings.ReplaceAll(f.Kind, "_", " "))
+}
+
+func renderBlocker(b gitdiff.Blocker, date string) string {
+	return fmt.Sprintf("- %s _(%s)_", b.Description, blockerMeta(b, date))
+}
@@ -3,104 +3,77 @@ package renderer
 import (
 	"fmt"
 	"md2slack/internal/gitdiff"
-	"strings"
+	"os"
 )
 
-func RenderReport(date string, groups []gitdiff.GroupedTask, allTasks []gitdiff.TaskChange, nextActions []string) string {
-	var sb strings.Builder
-
-	sb.WriteString(fmt.Sprintf("```\nDaily Status Report %s \n```\n\n", date))
-
-	var manualTasks []gitdiff.TaskChange
-	var commitTasks []gitdiff.TaskChange
-
-	for _, task := range allTasks {
-		if task.IsManual {
-			manualTasks = append(manualTasks, task)
-		} else {
-			commitTasks = append(commitTasks, task)
-		}
-	}
-	sb.WriteString("**Tasks**\n")
+// Report is the data handed to report templates.
+type Report struct {
+	Date        string                `json:"date"`
+	Repo        string                `json:"repo,omitempty"`
+	Groups      []gitdiff.GroupedTask `json:"groups,omitempty"`
+	Tasks       []gitdiff.TaskChange  `json:"tasks"`
+	NextActions []string              `json:"next_actions"`
+	Blockers    []gitdiff.Blocker     `json:"blockers"`
+	// Secrets are possible credentials found in the day's changes.
+	Secrets []gitdiff.SecretFinding `json:"secrets,omitempty"`
+}
 
-	if len(manualTasks) > 0 {
-		for _, task := range manualTasks {
-			sb.WriteString(renderTask(task))
-			sb.WriteString("\n")
+// ManualTasks returns the tasks that came from the extra context.
+func (r Report) ManualTasks() []gitdiff.TaskChange {
+	var out []gitdiff.TaskChange
+	for _, t := range r.Tasks {
+		if t.IsManual {
+			out = append(out, t)
 		}
-		sb.WriteString("\n")
 	}
+	return out
+}
 
-	if len(commitTasks) > 0 {
-		for _, task := range commitTasks {
-			sb.WriteString(renderTask(task))
-			sb.WriteString("\n")
+// CommitTasks returns the tasks synthesized from commits.
+func (r Report) CommitTasks() []gitdiff.TaskChange {
+	var out []gitdiff.TaskChange
+	for _, t := range r.Tasks {
+		if !t.IsManual {
+			out = append(out, t)
 		}
 	}
+	return out
+}
 
-	sb.WriteString("\n**Any Blockers?**\nNo\n\n")
-	sb.WriteString("**What do you plan to do next?**\n")
-	if len(nextActions) == 0 {
-		sb.WriteString("- Continue ongoing deliveries\n")
-	} else {
-		for _, action := range nextActions {
-			sb.WriteString(fmt.Sprintf("- %s\n", action))
+// OpenBlockers returns the blockers still open on the report date.
+func (r Report) OpenBlockers() []gitdiff.Blocker {
+	var out []gitdiff.Blocker
+	for _, b := range r.Blockers {
+		if b.IsOpenOn(r.Date) {
+			out = append(out, b)
 		}
 	}
-
-	return sb.String()
+	return out
 }
 
-func renderTask(t gitdiff.TaskChange) string {
-	hours := 1
-	if t.EstimatedHours != nil && *t.EstimatedHours > 0 {
-		hours = *t.EstimatedHours
-	}
-
-	intent := t.TaskIntent
-	if len(intent) > 0 {
-		intent = strings.ToUpper(intent[:1]) + intent[1:]
-	}
+// RenderReport renders the report with the default template.
+func RenderReport(date string, groups []gitdiff.GroupedTask, allTasks []gitdiff.TaskChange, nextActions []string, blockers []gitdiff.Blocker) string {
+	return RenderWith(DefaultTemplate, Report{
+		Date:        date,
+		Groups:      groups,
+		Tasks:       allTasks,
+		NextActions: nextActions,
+		Blockers:    blockers,
+	})
+}
 
-	status := strings.ToLower(strings.TrimSpace(t.Status))
-	statusLabel := "Done"
-	statusIcon := "✅"
-	switch status {
-	case "inprogress", "in_progress":
-		statusLabel = "In progress"
-		statusIcon = "🕒"
-	case "onhold", "on_hold":
-		statusLabel = "On hold"
-		statusIcon = "⏸"
-	case "done", "":
-		statusLabel = "Done"
-		statusIcon = "✅"
+// RenderWith renders the report with the named template, falling back to the
+// built-in default template if the named one is missing or fails.
+func RenderWith(name string, r Report) string {
+	out, err := Render(name, r)
+	if err == nil {
+		return out
 	}
-
-	var details []string
-	for _, line := range strings.Split(t.TechnicalWhy, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		details = append(details, fmt.Sprintf("  - %s", line))
+	fmt.Fprintf(os.Stderr, "Warning: report template %q failed: %v\n", name, err)
+	out, err = renderBuiltin(DefaultTemplate, r)
+	if err != nil {
+		// The built-in template is covered by tests; this only happens if it is broken.
+		return fmt.Sprintf("report rendering failed: %v", err)
 	}
-	var detailsStr string
-	if len(details) > 0 {
-		detailsStr = strings.Join(details, "\n") + "\n"
-	}
-
-	commitsLine := ""
-	if len(t.Commits) > 0 {
-		commitsLine = fmt.Sprintf("\n  - commits: `%s`", strings.Join(t.Commits, "`, `"))
-	}
-
-	return fmt.Sprintf("- %s — **%dh %s** %s\n%s%s",
-		intent,
-		hours,
-		statusLabel,
-		statusIcon,
-		detailsStr,
-		commitsLine,
-	)
+	return out
 }
@@ -0,0 +1,92 @@
+package renderer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// DefaultTemplate is the name of the built-in report layout.
+const DefaultTemplate = "default"
+
+const templateExt = ".tmpl"
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// userTemplateDir is where user templates are looked up, or "" when the home
+// directory is unknown.
+func userTemplateDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".md2slack", "templates")
+}
+
+// Render executes the named template against the report. User templates in
+// ~/.md2slack/templates take precedence over built-in ones.
+func Render(name string, r Report) (string, error) {
+	name = normalizeTemplateName(name)
+	if dir := userTemplateDir(); dir != "" {
+		content, err := os.ReadFile(filepath.Join(dir, name+templateExt))
+		if err == nil {
+			return execute(name, string(content), r)
+		}
+	}
+	return renderBuiltin(name, r)
+}
+
+func renderBuiltin(name string, r Report) (string, error) {
+	content, err := builtinTemplates.ReadFile("templates/" + name + templateExt)
+	if err != nil {
+		return "", fmt.Errorf("template %q not found", name)
+	}
+	return execute(name, string(content), r)
+}
+
+func execute(name string, content string, r Report) (string, error) {
+	tmpl, err := template.New(name).Funcs(Funcs()).Parse(content)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// TemplateNames lists built-in and user templates.
+func TemplateNames() []string {
+	seen := make(map[string]struct{})
+	entries, _ := builtinTemplates.ReadDir("templates")
+	for _, e := range entries {
+		seen[strings.TrimSuffix(e.Name(), templateExt)] = struct{}{}
+	}
+	if dir := userTemplateDir(); dir != "" {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*"+templateExt))
+		for _, m := range matches {
+			seen[strings.TrimSuffix(filepath.Base(m), templateExt)] = struct{}{}
+		}
+	}
+	var names []string
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func normalizeTemplateName(name string) string {
+	name = strings.TrimSuffix(filepath.Base(strings.TrimSpace(name)), templateExt)
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		return DefaultTemplate
+	}
+	return name
+}
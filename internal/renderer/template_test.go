@@ -0,0 +1,227 @@
+package renderer
+
+import (
+	"encoding/json"
+	"md2slack/internal/gitdiff"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sampleReport() Report {
+	hours := 3
+	return Report{
+		Date: "2026-02-05",
+		Tasks: []gitdiff.TaskChange{
+			{TaskIntent: "pair on onboarding", IsManual: true, Status: "done"},
+			{TaskIntent: "add retry to checkout", EstimatedHours: &hours, Status: "in_progress", TechnicalWhy: "wrap client\n\nbackoff on 5xx", Commits: []string{"abc123", "def456"}},
+			{TaskIntent: "drop legacy flag", Status: "on_hold"},
+		},
+		NextActions: []string{"ship checkout retries"},
+		Blockers: []gitdiff.Blocker{
+			{Description: "waiting on staging creds", Owner: "carlos", OpenedOn: "2026-02-03"},
+			{Description: "resolved one", OpenedOn: "2026-02-01", ResolvedOn: "2026-02-04"},
+		},
+	}
+}
+
+func TestDefaultTemplateLayout(t *testing.T) {
+	r := sampleReport()
+	got := RenderReport(r.Date, nil, r.Tasks, r.NextActions, r.Blockers)
+	want := "```\nDaily Status Report 2026-02-05 \n```\n\n" +
+		"**Tasks**\n" +
+		"- Pair on onboarding — **1h Done** ✅\n\n\n" +
+		"- Add retry to checkout — **3h In progress** 🕒\n  - wrap client\n  - backoff on 5xx\n\n  - commits: `abc123`, `def456`\n" +
+		"- Drop legacy flag — **1h On hold** ⏸\n\n" +
+		"\n**Any Blockers?**\n" +
+		"- waiting on staging creds _(owner: @carlos, open 2 days)_\n" +
+		"\n**What do you plan to do next?**\n" +
+		"- ship checkout retries\n"
+	if got != want {
+		t.Fatalf("unexpected default report:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestTaskIssueLinks(t *testing.T) {
+	r := sampleReport()
+	r.Tasks[1].Issues = []gitdiff.IssueRef{
+		{Key: "PROJ-12", URL: "https://acme.atlassian.net/browse/PROJ-12", Title: "Retry webhooks", Status: "In Progress"},
+		{Key: "#456"},
+	}
+	want := "[PROJ-12](https://acme.atlassian.net/browse/PROJ-12), #456"
+
+	got := RenderWith(DefaultTemplate, r)
+	if !strings.Contains(got, "  - commits: `abc123`, `def456`\n  - issues: [PROJ-12](https://acme.atlassian.net/browse/PROJ-12) Retry webhooks (In Progress); #456\n") {
+		t.Fatalf("default template is missing issue links:\n%s", got)
+	}
+	for _, name := range []string{"yesterday_today", "done_doing_next"} {
+		out, err := Render(name, r)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(out, "Add retry to checkout ("+want+")") {
+			t.Fatalf("%s: missing issue links:\n%s", name, out)
+		}
+	}
+}
+
+func TestTaskUntestedFiles(t *testing.T) {
+	r := sampleReport()
+	r.Tasks[1].UntestedFiles = []string{"checkout/retry.go", "checkout/client.go"}
+
+	got := RenderWith(DefaultTemplate, r)
+	if !strings.Contains(got, "  - commits: `abc123`, `def456`\n  - ⚠️ untested: `checkout/retry.go`, `checkout/client.go`\n") {
+		t.Fatalf("default template is missing untested files:\n%s", got)
+	}
+	if strings.Count(got, "untested") != 1 {
+		t.Fatalf("expected only the task with untested files to be flagged:\n%s", got)
+	}
+}
+
+func TestReportWarnsAboutSecrets(t *testing.T) {
+	r := sampleReport()
+	r.Secrets = []gitdiff.SecretFinding{
+		{Commit: "abc123", File: "config/prod.go", Line: 12, Kind: "aws_access_key"},
+		{Commit: "def456", Kind: "high_entropy"},
+	}
+	want := "- `config/prod.go:12` in `abc123` (aws access key)\n- `commit message` in `def456` (high entropy)\n"
+	for _, name := range TemplateNames() {
+		out, err := Render(name, r)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(out, "Possible secrets committed") || !strings.Contains(out, want) {
+			t.Fatalf("%s: missing secrets warning:\n%s", name, out)
+		}
+	}
+	if strings.Contains(RenderWith(DefaultTemplate, sampleReport()), "secrets") {
+		t.Fatal("expected no warning without secrets")
+	}
+}
+
+func TestDefaultTemplateEmptySections(t *testing.T) {
+	got := RenderReport("2026-02-05", nil, nil, nil, nil)
+	if !strings.Contains(got, "**Any Blockers?**\nNo\n\n") || !strings.HasSuffix(got, "- Continue ongoing deliveries\n") {
+		t.Fatalf("unexpected empty report: %q", got)
+	}
+}
+
+func TestBuiltinTemplatesRender(t *testing.T) {
+	for _, name := range []string{"yesterday_today", "done_doing_next"} {
+		out, err := Render(name, sampleReport())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(out, "Pair on onboarding") || !strings.Contains(out, "waiting on staging creds") {
+			t.Fatalf("%s: missing content:\n%s", name, out)
+		}
+	}
+}
+
+func TestUserTemplatesComeFromHomeOnly(t *testing.T) {
+	home, cwd := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(cwd)
+	dir := filepath.Join(home, ".md2slack", "templates")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "default.tmpl"), []byte("home {{.Date}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Templates next to wherever the command runs are not picked up.
+	if err := os.MkdirAll(filepath.Join(cwd, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cwd, "templates", "standup.tmpl"), []byte("cwd"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Render("default", sampleReport())
+	if err != nil || out != "home 2026-02-05" {
+		t.Fatalf("expected the home template, got %q %v", out, err)
+	}
+	if _, err := Render("standup", sampleReport()); err == nil {
+		t.Fatal("expected templates in the working directory to be ignored")
+	}
+}
+
+func TestUnknownTemplateFallsBackToDefault(t *testing.T) {
+	if _, err := Render("does-not-exist", sampleReport()); err == nil {
+		t.Fatal("expected error for unknown template")
+	}
+	out := RenderWith("does-not-exist", sampleReport())
+	if !strings.HasPrefix(out, "```\nDaily Status Report 2026-02-05") {
+		t.Fatalf("expected default fallback, got %q", out)
+	}
+}
+
+func TestMarkdownToHTMLDropsRawHTML(t *testing.T) {
+	out, err := MarkdownToHTML("- fix <img src=x onerror=alert(1)> in [login](javascript:alert(1))\n\n<script>alert(1)</script>\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{"<img", "<script", "javascript:"} {
+		if strings.Contains(out, leak) {
+			t.Fatalf("expected %q dropped:\n%s", leak, out)
+		}
+	}
+	if !strings.Contains(out, "<li>fix") {
+		t.Fatalf("expected the markdown rendered:\n%s", out)
+	}
+}
+
+func TestRenderFormats(t *testing.T) {
+	r := sampleReport()
+	md := RenderReport(r.Date, nil, r.Tasks, r.NextActions, r.Blockers)
+
+	text, err := RenderFormat("text", md, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(text, "**") || strings.Contains(text, "```") || strings.Contains(text, "`abc123`") {
+		t.Fatalf("expected markdown stripped from text:\n%s", text)
+	}
+	if !strings.Contains(text, "- Add retry to checkout — 3h In progress 🕒") || !strings.Contains(text, "(owner: @carlos, open 2 days)") {
+		t.Fatalf("unexpected text output:\n%s", text)
+	}
+
+	jira, err := RenderFormat("jira-wiki", md, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"{noformat}", "* Add retry to checkout — *3h In progress* 🕒", "** commits: {{abc123}}, {{def456}}", "*Any Blockers?*"} {
+		if !strings.Contains(jira, want) {
+			t.Fatalf("expected %q in jira output:\n%s", want, jira)
+		}
+	}
+
+	out, err := RenderFormat("json", md, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Date     string            `json:"date"`
+		Blockers []gitdiff.Blocker `json:"blockers"`
+		Markdown string            `json:"markdown"`
+	}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Date != r.Date || len(decoded.Blockers) != 1 || decoded.Markdown != md {
+		t.Fatalf("unexpected json output: %+v", decoded)
+	}
+
+	doc, err := RenderFormat("html", md, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(doc, "<!DOCTYPE html>") || !strings.Contains(doc, "<strong>Tasks</strong>") {
+		t.Fatalf("unexpected html output:\n%s", doc)
+	}
+
+	if _, err := RenderFormat("pdf", md, r); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
@@ -358,3 +358,14 @@ func (c *converter) makeTextElement(text string) map[string]interface{} {
 
 	return el
 }
+
+// Notifier sends reports to the configured Slack channel.
+type Notifier struct {
+	Config *config.SlackConfig
+}
+
+func (n *Notifier) Name() string { return "slack" }
+
+func (n *Notifier) Send(subject string, markdown string) error {
+	return SendMarkdown(n.Config, markdown)
+}
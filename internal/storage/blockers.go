@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"md2slack/internal/gitdiff"
+	"strings"
+)
+
+// LoadOpenBlockers returns the blockers of a repo that are still open on date,
+// oldest first. Blockers opened on earlier days are carried forward until they
+// are resolved.
+func LoadOpenBlockers(repoName string, date string) ([]gitdiff.Blocker, error) {
+	if err := initDB(); err != nil {
+		return nil, err
+	}
+
+	day := gitdiff.ISODate(date)
+	rows, err := db.Query(`
+		SELECT id, description, owner, opened_on, resolved_on FROM blockers
+		WHERE repo_name = ? AND opened_on <= ? AND (resolved_on = '' OR resolved_on > ?)
+		ORDER BY opened_on, id`, repoName, day, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blockers []gitdiff.Blocker
+	for rows.Next() {
+		var b gitdiff.Blocker
+		if err := rows.Scan(&b.ID, &b.Description, &b.Owner, &b.OpenedOn, &b.ResolvedOn); err != nil {
+			return nil, err
+		}
+		blockers = append(blockers, b)
+	}
+	return blockers, rows.Err()
+}
+
+// SaveBlockers inserts blockers without an ID and updates the rest. A new
+// blocker whose description matches one that is already open is merged into
+// it, so repeating the same blocker on the command line does not duplicate it.
+func SaveBlockers(repoName string, blockers []gitdiff.Blocker) ([]gitdiff.Blocker, error) {
+	if err := initDB(); err != nil {
+		return nil, err
+	}
+
+	out := make([]gitdiff.Blocker, 0, len(blockers))
+	for _, b := range blockers {
+		b.Description = strings.TrimSpace(b.Description)
+		if b.Description == "" {
+			continue
+		}
+		b.OpenedOn = gitdiff.ISODate(b.OpenedOn)
+		b.ResolvedOn = gitdiff.ISODate(b.ResolvedOn)
+
+		if b.ID == 0 {
+			var existing int64
+			err := db.QueryRow(`
+				SELECT id FROM blockers
+				WHERE repo_name = ? AND lower(description) = lower(?) AND resolved_on = ''`,
+				repoName, b.Description).Scan(&existing)
+			if err == nil {
+				b.ID = existing
+			}
+		}
+
+		if b.ID == 0 {
+			res, err := db.Exec(`
+				INSERT INTO blockers (repo_name, description, owner, opened_on, resolved_on)
+				VALUES (?, ?, ?, ?, ?)`,
+				repoName, b.Description, b.Owner, b.OpenedOn, b.ResolvedOn)
+			if err != nil {
+				return out, err
+			}
+			if b.ID, err = res.LastInsertId(); err != nil {
+				return out, err
+			}
+		} else {
+			_, err := db.Exec(`
+				UPDATE blockers SET
+					description = ?,
+					owner = CASE WHEN ? = '' THEN owner ELSE ? END,
+					resolved_on = ?
+				WHERE id = ? AND repo_name = ?`,
+				b.Description, b.Owner, b.Owner, b.ResolvedOn, b.ID, repoName)
+			if err != nil {
+				return out, err
+			}
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
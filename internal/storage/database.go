@@ -43,6 +43,14 @@ func initDB() error {
 			data TEXT,
 			report TEXT,
 			UNIQUE(repo_name, date)
+		);
+		CREATE TABLE IF NOT EXISTS blockers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repo_name TEXT,
+			description TEXT,
+			owner TEXT,
+			opened_on TEXT,
+			resolved_on TEXT DEFAULT ''
 		);`
 		_, err = db.Exec(schema)
 	})
@@ -0,0 +1,141 @@
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Notifier posts reports to a Teams channel through an incoming webhook.
+type Notifier struct {
+	WebhookURL string
+}
+
+func (n *Notifier) Name() string { return "teams" }
+
+func (n *Notifier) Send(subject string, markdown string) error {
+	return SendMarkdown(n.WebhookURL, subject, markdown)
+}
+
+// SendMarkdown converts the report into an Adaptive Card and posts it.
+func SendMarkdown(webhookURL string, subject string, markdown string) error {
+	if webhookURL == "" {
+		return fmt.Errorf("please configure webhook_url in the [teams] section of config.ini")
+	}
+	message := map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content":     ConvertToCard(subject, markdown),
+			},
+		},
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("teams error: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+var (
+	inlineCodeRe = regexp.MustCompile("`([^`]+)`")
+	boldLineRe   = regexp.MustCompile(`^\*\*(.+)\*\*$`)
+	headingRe    = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+)
+
+// ConvertToCard turns report markdown into an Adaptive Card. Code fences
+// become monospace blocks, bold-only lines and headings become section
+// titles, and everything else is passed through as TextBlock markdown.
+func ConvertToCard(subject string, markdown string) map[string]interface{} {
+	body := []interface{}{}
+	if subject != "" {
+		body = append(body, textBlock(subject, map[string]interface{}{"size": "Large", "weight": "Bolder"}))
+	}
+
+	var para []string
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		// TextBlock needs blank lines between list items to keep them on separate rows
+		body = append(body, textBlock(strings.Join(para, "\n\n"), nil))
+		para = nil
+	}
+
+	var code []string
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if inFence {
+				text := strings.TrimSpace(strings.Join(code, "\n"))
+				// The report title is usually fenced; skip it when it repeats the subject
+				if text != "" && text != strings.TrimSpace(subject) {
+					body = append(body, textBlock(text, map[string]interface{}{"fontType": "Monospace"}))
+				}
+				code = nil
+			} else {
+				flush()
+			}
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			code = append(code, line)
+			continue
+		}
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if m := boldLineRe.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			body = append(body, textBlock(m[1], map[string]interface{}{"weight": "Bolder", "spacing": "Medium"}))
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			body = append(body, textBlock(m[1], map[string]interface{}{"weight": "Bolder", "spacing": "Medium"}))
+			continue
+		}
+		// Adaptive Card markdown has no inline code
+		para = append(para, inlineCodeRe.ReplaceAllString(strings.TrimRight(line, " "), "$1"))
+	}
+	flush()
+
+	return map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+}
+
+func textBlock(text string, extra map[string]interface{}) map[string]interface{} {
+	block := map[string]interface{}{
+		"type": "TextBlock",
+		"text": text,
+		"wrap": true,
+	}
+	for k, v := range extra {
+		block[k] = v
+	}
+	return block
+}
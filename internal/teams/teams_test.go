@@ -0,0 +1,45 @@
+package teams
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const report = "```\nDaily Status Report 2026-02-05 \n```\n\n**Tasks**\n- Ship teams — **1h Done** ✅\n  - commits: `abc123`\n\n**Any Blockers?**\nNo\n"
+
+func TestConvertToCard(t *testing.T) {
+	card := ConvertToCard("Daily Status Report 2026-02-05", report)
+	body := card["body"].([]interface{})
+	var texts []string
+	for _, b := range body {
+		texts = append(texts, b.(map[string]interface{})["text"].(string))
+	}
+	got := strings.Join(texts, "|")
+	want := "Daily Status Report 2026-02-05|Tasks|- Ship teams — **1h Done** ✅\n\n  - commits: abc123|Any Blockers?|No"
+	if got != want {
+		t.Fatalf("unexpected card text:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestSendMarkdownPostsAdaptiveCard(t *testing.T) {
+	var payload map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &payload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	if err := SendMarkdown(srv.URL, "Report", report); err != nil {
+		t.Fatal(err)
+	}
+	attachments := payload["attachments"].([]interface{})
+	att := attachments[0].(map[string]interface{})
+	if att["contentType"] != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("unexpected attachment: %#v", att)
+	}
+}
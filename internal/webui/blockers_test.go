@@ -0,0 +1,68 @@
+package webui
+
+import (
+	"md2slack/internal/gitdiff"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleBlockersUpdatesOnlyTheCurrentReport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "billing")
+	if _, err := gitdiff.Git(filepath.Dir(dir), "init", "-q", dir); err != nil {
+		t.Fatal(err)
+	}
+	stored := map[string][]gitdiff.Blocker{}
+	var saves int
+	var saved string
+	s := &Server{}
+	s.Reset(nil, "02-05-2026", "billing")
+	s.SetBlockerHandlers(func(repoName, date string) ([]gitdiff.Blocker, error) {
+		return stored[repoName+" "+date], nil
+	}, func(repoName, date string, blockers []gitdiff.Blocker) ([]gitdiff.Blocker, error) {
+		stored[repoName+" "+date] = blockers
+		return blockers, nil
+	})
+	s.SetHandlers(nil, nil, func(date string, tasks []gitdiff.TaskChange, report string) error {
+		saves++
+		saved = report
+		return nil
+	})
+
+	post := func(date, text string) {
+		t.Helper()
+		q := url.Values{"repo": {dir}, "date": {date}}
+		rec := httptest.NewRecorder()
+		body := `{"blockers":[{"description":"` + text + `"}]}`
+		s.handleBlockers(rec, httptest.NewRequest(http.MethodPost, "/api/blockers?"+q.Encode(), strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	// Another day leaves the report on screen alone.
+	post("2026-02-04", "waiting on staging")
+	if len(s.GetBlockers()) != 0 || saves != 0 {
+		t.Fatalf("expected the current report untouched, got %+v after %d saves", s.GetBlockers(), saves)
+	}
+
+	// The same day, written differently, updates it.
+	post("2026-02-05", "waiting on the API key")
+	if got := s.GetBlockers(); len(got) != 1 || got[0].Description != "waiting on the API key" || saves != 1 {
+		t.Fatalf("expected the current report updated, got %+v after %d saves", got, saves)
+	}
+	if !strings.Contains(saved, "waiting on the API key") {
+		t.Fatalf("expected the saved report to include the new blocker:\n%s", saved)
+	}
+
+	// Another repo on the same day leaves it alone too.
+	s.Reset(nil, "2026-02-05", "web")
+	s.SetBlockers(nil)
+	post("2026-02-05", "waiting on design")
+	if len(s.GetBlockers()) != 0 || saves != 1 {
+		t.Fatalf("expected another repo's report untouched, got %+v after %d saves", s.GetBlockers(), saves)
+	}
+}
@@ -32,6 +32,9 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 
 	s.mu.Lock()
 	currentTasks := s.state.Tasks
+	currentBlockers := s.state.Blockers
+	repoName := s.state.Repo
+	date := s.state.Date
 	s.mu.Unlock()
 
 	if s.onChatWithCallbacks == nil {
@@ -85,11 +88,12 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[handleChat] Calling chat handler with %d current tasks", len(currentTasks))
 
 	var updatedTasks []gitdiff.TaskChange
+	var updatedBlockers []gitdiff.Blocker
 	var responseText string
 	var err error
 
 	log.Printf("[handleChat] Using onChatWithCallbacks")
-	updatedTasks, responseText, err = s.onChatWithCallbacks(req.History, currentTasks, callbacks)
+	updatedTasks, updatedBlockers, responseText, err = s.onChatWithCallbacks(req.History, currentTasks, currentBlockers, date, callbacks)
 
 	if err != nil {
 		log.Printf("[handleChat] ERROR: chat handler returned error: %v", err)
@@ -99,6 +103,19 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("[handleChat] chat returned: %d tasks, response length: %d", len(updatedTasks), len(responseText))
 
+	if s.onSaveBlockers != nil && repoName != "" {
+		if _, err := s.onSaveBlockers(repoName, date, updatedBlockers); err != nil {
+			log.Printf("[handleChat] ERROR: saving blockers: %v", err)
+		} else if s.onLoadBlockers != nil {
+			if open, err := s.onLoadBlockers(repoName, date); err == nil {
+				updatedBlockers = open
+			}
+		}
+	}
+	s.mu.Lock()
+	s.state.Blockers = updatedBlockers
+	s.mu.Unlock()
+
 	s.SetTasks(updatedTasks, s.state.NextActions)
 	if s.onSave != nil {
 		_ = s.onSave(s.state.Date, updatedTasks, s.state.Report)
@@ -106,8 +123,9 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 
 	// Send final response
 	sendEvent("message", map[string]interface{}{
-		"text":  responseText,
-		"tasks": updatedTasks,
+		"text":     responseText,
+		"tasks":    updatedTasks,
+		"blockers": updatedBlockers,
 	})
 	sendEvent("done", map[string]string{"status": "complete"})
 	log.Printf("[handleChat] Chat completed successfully")
@@ -0,0 +1,34 @@
+package webui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"md2slack/internal/gitdiff"
+)
+
+func TestHandleExportFormats(t *testing.T) {
+	s := &Server{}
+	s.state.Date = "2026-02-05"
+	s.SetTasks([]gitdiff.TaskChange{{TaskIntent: "ship export", Status: "done"}}, nil)
+
+	rec := httptest.NewRecorder()
+	s.handleExport(rec, httptest.NewRequest(http.MethodGet, "/api/export?format=jira-wiki&download=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "* Ship export — *1h Done* ✅") {
+		t.Fatalf("unexpected jira export: %s", rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, "report-2026-02-05.txt") {
+		t.Fatalf("unexpected content disposition %q", cd)
+	}
+
+	rec = httptest.NewRecorder()
+	s.handleExport(rec, httptest.NewRequest(http.MethodGet, "/api/export?format=docx", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected bad request for unknown format, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,41 @@
+package webui
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleSendChannels(t *testing.T) {
+	var sent []string
+	s := &Server{}
+	s.SetReport("**Tasks**")
+	s.SetHandlers(func(channel string, report string) error {
+		if channel == "discord" {
+			return errors.New("webhook down")
+		}
+		sent = append(sent, channel)
+		return nil
+	}, nil, nil)
+	s.SetDestinationsHandler(func(repoName string) ([]string, []string) {
+		return []string{"email", "slack", "teams"}, []string{"slack", "teams"}
+	})
+
+	rec := httptest.NewRecorder()
+	s.handleSend(rec, httptest.NewRequest(http.MethodPost, "/api/send", nil))
+	if rec.Code != http.StatusNoContent || strings.Join(sent, ",") != "slack,teams" {
+		t.Fatalf("expected default destinations, got %d %v", rec.Code, sent)
+	}
+
+	sent = nil
+	rec = httptest.NewRecorder()
+	s.handleSend(rec, httptest.NewRequest(http.MethodPost, "/api/send?channel=email&channel=discord", nil))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "discord: webhook down") {
+		t.Fatalf("expected discord failure, got %d %q", rec.Code, rec.Body.String())
+	}
+	if strings.Join(sent, ",") != "email" {
+		t.Fatalf("expected email still sent, got %v", sent)
+	}
+}
@@ -1,13 +1,11 @@
 package webui
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"md2slack/internal/gitdiff"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -142,34 +140,12 @@ func scanUsers(repoPath string) ([]string, error) {
 	if strings.TrimSpace(repoPath) == "" {
 		return nil, fmt.Errorf("repo path is required")
 	}
-	nameOut, _ := runGitInRepo(repoPath, "git config user.name")
-	logOut, _ := runGitInRepo(repoPath, "git log --format=%an -n 200")
+	name, _ := gitdiff.GetUserName(repoPath)
+	authors, _ := gitdiff.GetRecentAuthors(repoPath, 200)
 	var users []string
-	if strings.TrimSpace(nameOut) != "" {
-		users = append(users, strings.TrimSpace(nameOut))
-	}
-	for _, line := range strings.Split(logOut, "\n") {
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-		users = append(users, strings.TrimSpace(line))
+	if strings.TrimSpace(name) != "" {
+		users = append(users, strings.TrimSpace(name))
 	}
+	users = append(users, authors...)
 	return normalizeList(users), nil
 }
-
-func runGitInRepo(repoPath string, cmd string) (string, error) {
-	c := exec.Command("bash", "-c", cmd)
-	c.Dir = repoPath
-	var out bytes.Buffer
-	var errOut bytes.Buffer
-	c.Stdout = &out
-	c.Stderr = &errOut
-	if err := c.Run(); err != nil {
-		msg := strings.TrimSpace(errOut.String())
-		if msg == "" {
-			msg = err.Error()
-		}
-		return "", fmt.Errorf("git command failed: %s", msg)
-	}
-	return strings.TrimSpace(out.String()), nil
-}
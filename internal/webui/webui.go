@@ -1,7 +1,6 @@
 package webui
 
 import (
-	"bytes"
 	"embed"
 	"encoding/json"
 	"html"
@@ -13,9 +12,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/yuin/goldmark"
-	goldhtml "github.com/yuin/goldmark/renderer/html"
-
 	"md2slack/internal/gitdiff"
 	"md2slack/internal/renderer"
 )
@@ -50,16 +46,18 @@ type Stage struct {
 }
 
 type State struct {
-	Repo        string               `json:"repo"`
-	Date        string               `json:"date"`
-	Stages      []Stage              `json:"stages"`
-	Logs        []string             `json:"logs"`
-	Errors      []string             `json:"errors"`
-	StatusLine  string               `json:"status_line"`
-	Report      string               `json:"report"`
-	ReportHTML  string               `json:"report_html"`
-	Tasks       []gitdiff.TaskChange `json:"tasks"`
-	NextActions []string             `json:"next_actions"`
+	Repo        string                  `json:"repo"`
+	Date        string                  `json:"date"`
+	Stages      []Stage                 `json:"stages"`
+	Logs        []string                `json:"logs"`
+	Errors      []string                `json:"errors"`
+	StatusLine  string                  `json:"status_line"`
+	Report      string                  `json:"report"`
+	ReportHTML  string                  `json:"report_html"`
+	Tasks       []gitdiff.TaskChange    `json:"tasks"`
+	NextActions []string                `json:"next_actions"`
+	Blockers    []gitdiff.Blocker       `json:"blockers"`
+	Secrets     []gitdiff.SecretFinding `json:"secrets,omitempty"`
 }
 
 type RunRequest struct {
@@ -79,14 +77,18 @@ type Server struct {
 	state               State
 	stageNames          []string
 	runCh               chan RunRequest
-	onSend              func(report string) error
+	onSend              func(channel string, report string) error
 	onRefine            func(prompt string, tasks []gitdiff.TaskChange) ([]gitdiff.TaskChange, error)
 	onSave              func(date string, tasks []gitdiff.TaskChange, report string) error
 	onAction            func(action string, selected []int, tasks []gitdiff.TaskChange) ([]gitdiff.TaskChange, error)
-	onChatWithCallbacks func(history []OpenAIMessage, tasks []gitdiff.TaskChange, callbacks ChatCallbacks) ([]gitdiff.TaskChange, string, error)
+	onChatWithCallbacks func(history []OpenAIMessage, tasks []gitdiff.TaskChange, blockers []gitdiff.Blocker, date string, callbacks ChatCallbacks) ([]gitdiff.TaskChange, []gitdiff.Blocker, string, error)
 	onUpdateTask        func(index int, task gitdiff.TaskChange, tasks []gitdiff.TaskChange) ([]gitdiff.TaskChange, error)
 	onLoadHistory       func(repo string, date string) ([]gitdiff.TaskChange, string, error)
 	onClearTasks        func(repo string, date string) error
+	onLoadBlockers      func(repoName string, date string) ([]gitdiff.Blocker, error)
+	onSaveBlockers      func(repoName string, date string, blockers []gitdiff.Blocker) ([]gitdiff.Blocker, error)
+	reportTemplate      func(repoName string) string
+	onDestinations      func(repoName string) (available []string, selected []string)
 }
 
 type ChatCallbacks struct {
@@ -102,7 +104,7 @@ func Start(addr string, stageNames []string) *Server {
 	return s
 }
 
-func (s *Server) SetHandlers(onSend func(string) error, onRefine func(string, []gitdiff.TaskChange) ([]gitdiff.TaskChange, error), onSave func(string, []gitdiff.TaskChange, string) error) {
+func (s *Server) SetHandlers(onSend func(string, string) error, onRefine func(string, []gitdiff.TaskChange) ([]gitdiff.TaskChange, error), onSave func(string, []gitdiff.TaskChange, string) error) {
 	s.onSend = onSend
 	s.onRefine = onRefine
 	s.onSave = onSave
@@ -117,7 +119,7 @@ func (s *Server) SetActionHandler(
 }
 
 func (s *Server) SetChatWithCallbacks(
-	onChatWithCallbacks func(history []OpenAIMessage, tasks []gitdiff.TaskChange, callbacks ChatCallbacks) ([]gitdiff.TaskChange, string, error),
+	onChatWithCallbacks func(history []OpenAIMessage, tasks []gitdiff.TaskChange, blockers []gitdiff.Blocker, date string, callbacks ChatCallbacks) ([]gitdiff.TaskChange, []gitdiff.Blocker, string, error),
 ) {
 	s.onChatWithCallbacks = onChatWithCallbacks
 }
@@ -130,6 +132,27 @@ func (s *Server) SetLoadClearHandlers(
 	s.onClearTasks = onClearTasks
 }
 
+func (s *Server) SetBlockerHandlers(
+	onLoadBlockers func(repoName string, date string) ([]gitdiff.Blocker, error),
+	onSaveBlockers func(repoName string, date string, blockers []gitdiff.Blocker) ([]gitdiff.Blocker, error),
+) {
+	s.onLoadBlockers = onLoadBlockers
+	s.onSaveBlockers = onSaveBlockers
+}
+
+// SetDestinationsHandler reports which destinations are configured for a
+// repo and which are selected by default.
+func (s *Server) SetDestinationsHandler(onDestinations func(repoName string) (available []string, selected []string)) {
+	s.onDestinations = onDestinations
+}
+
+// SetReportTemplate sets how the report template is chosen for a repo.
+func (s *Server) SetReportTemplate(reportTemplate func(repoName string) string) {
+	s.mu.Lock()
+	s.reportTemplate = reportTemplate
+	s.mu.Unlock()
+}
+
 func (s *Server) RunChannel() <-chan RunRequest {
 	return s.runCh
 }
@@ -147,6 +170,7 @@ func (s *Server) Reset(stageNames []string, date string, repo string) {
 	s.state.Logs = nil
 	s.state.Errors = nil
 	s.state.StatusLine = ""
+	s.state.Secrets = nil
 }
 
 func (s *Server) StageStart(idx int, name string) {
@@ -209,14 +233,67 @@ func (s *Server) GetTasks() []gitdiff.TaskChange {
 
 func (s *Server) SetTasks(tasks []gitdiff.TaskChange, nextActions []string) {
 	s.mu.Lock()
-	date := s.state.Date
 	s.state.Tasks = tasks
 	s.state.NextActions = nextActions
 	s.mu.Unlock()
 
 	// Automatically re-generate report whenever tasks change
-	report := renderer.RenderReport(date, nil, tasks, nextActions)
-	s.SetReport(report)
+	s.renderReport()
+}
+
+// GetRepo returns the name of the repo whose report is on screen.
+func (s *Server) GetRepo() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Repo
+}
+
+func (s *Server) GetBlockers() []gitdiff.Blocker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]gitdiff.Blocker{}, s.state.Blockers...)
+}
+
+func (s *Server) SetBlockers(blockers []gitdiff.Blocker) {
+	s.mu.Lock()
+	s.state.Blockers = blockers
+	s.mu.Unlock()
+
+	s.renderReport()
+}
+
+// SetSecrets records the possible secrets found in the analyzed changes,
+// which the report warns about. SetBlockers or SetTasks re-renders it.
+func (s *Server) SetSecrets(secrets []gitdiff.SecretFinding) {
+	s.mu.Lock()
+	s.state.Secrets = secrets
+	s.mu.Unlock()
+}
+
+// ReportData returns the current report contents for rendering with another
+// template or format.
+func (s *Server) ReportData() renderer.Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return renderer.Report{
+		Date:        s.state.Date,
+		Repo:        s.state.Repo,
+		Tasks:       append([]gitdiff.TaskChange{}, s.state.Tasks...),
+		NextActions: append([]string{}, s.state.NextActions...),
+		Blockers:    append([]gitdiff.Blocker{}, s.state.Blockers...),
+		Secrets:     append([]gitdiff.SecretFinding{}, s.state.Secrets...),
+	}
+}
+
+func (s *Server) renderReport() {
+	data := s.ReportData()
+	s.mu.Lock()
+	name := renderer.DefaultTemplate
+	if s.reportTemplate != nil {
+		name = s.reportTemplate(data.Repo)
+	}
+	s.mu.Unlock()
+	s.SetReport(renderer.RenderWith(name, data))
 }
 
 func (s *Server) SetReport(report string) {
@@ -242,6 +319,9 @@ func (s *Server) startHTTP() {
 	mux.HandleFunc("/api/git-graph", s.handleGitGraph)
 	mux.HandleFunc("/api/load-history", s.handleLoadHistory)
 	mux.HandleFunc("/api/clear-tasks", s.handleClearTasks)
+	mux.HandleFunc("/api/blockers", s.handleBlockers)
+	mux.HandleFunc("/api/export", s.handleExport)
+	mux.HandleFunc("/api/destinations", s.handleDestinations)
 
 	sub, _ := fs.Sub(distFS, "dist")
 	fileServer := http.FileServer(http.FS(sub))
@@ -362,14 +442,56 @@ func (s *Server) handleSend(w http.ResponseWriter, r *http.Request) {
 	}
 	s.mu.Lock()
 	report := s.state.Report
+	repo := s.state.Repo
 	s.mu.Unlock()
-	if err := s.onSend(report); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+	var channels []string
+	for _, c := range r.URL.Query()["channel"] {
+		for _, part := range strings.Split(c, ",") {
+			if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+				channels = append(channels, part)
+			}
+		}
+	}
+	if len(channels) == 0 && s.onDestinations != nil {
+		_, channels = s.onDestinations(repo)
+	}
+	if len(channels) == 0 {
+		channels = []string{"slack"}
+	}
+
+	var failed []string
+	for _, channel := range channels {
+		if err := s.onSend(channel, report); err != nil {
+			failed = append(failed, channel+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		http.Error(w, strings.Join(failed, "\n"), http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (s *Server) handleDestinations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	available, selected := []string{"slack"}, []string{"slack"}
+	if s.onDestinations != nil {
+		s.mu.Lock()
+		repo := s.state.Repo
+		s.mu.Unlock()
+		available, selected = s.onDestinations(repo)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string][]string{
+		"available": available,
+		"selected":  selected,
+	})
+}
+
 func (s *Server) handleAction(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -588,14 +710,41 @@ func renderMarkdown(md string) string {
 	if strings.TrimSpace(md) == "" {
 		return "<em>(empty)</em>"
 	}
-	var buf bytes.Buffer
-	mdr := goldmark.New(goldmark.WithRendererOptions(
-		goldhtml.WithUnsafe(),
-	))
-	if err := mdr.Convert([]byte(md), &buf); err != nil {
+	out, err := renderer.MarkdownToHTML(md)
+	if err != nil {
 		return "<pre>" + html.EscapeString(md) + "</pre>"
 	}
-	return buf.String()
+	return out
+}
+
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	format, ok := renderer.LookupFormat(r.URL.Query().Get("format"))
+	if !ok {
+		http.Error(w, "unknown format; available: "+strings.Join(renderer.FormatNames(), ", "), http.StatusBadRequest)
+		return
+	}
+	data := s.ReportData()
+	s.mu.Lock()
+	report := s.state.Report
+	s.mu.Unlock()
+	out, err := format.Render(report, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	filename := "report"
+	if data.Date != "" {
+		filename += "-" + data.Date
+	}
+	w.Header().Set("Content-Type", format.ContentType)
+	if r.URL.Query().Get("download") != "" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+"."+format.Extension+`"`)
+	}
+	_, _ = w.Write([]byte(out))
 }
 
 func (s *Server) handleLoadHistory(w http.ResponseWriter, r *http.Request) {
@@ -681,3 +830,68 @@ func (s *Server) handleClearTasks(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (s *Server) handleBlockers(w http.ResponseWriter, r *http.Request) {
+	if s.onLoadBlockers == nil || s.onSaveBlockers == nil {
+		http.Error(w, "blockers not configured", http.StatusBadRequest)
+		return
+	}
+	repo := r.URL.Query().Get("repo")
+	date := r.URL.Query().Get("date")
+	if repo == "" || date == "" {
+		http.Error(w, "repo and date are required", http.StatusBadRequest)
+		return
+	}
+	repoName := gitdiff.GetRepoNameAt(repo)
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var payload struct {
+			Blockers []gitdiff.Blocker `json:"blockers"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "invalid json", http.StatusBadRequest)
+			return
+		}
+		for i := range payload.Blockers {
+			if payload.Blockers[i].OpenedOn == "" {
+				payload.Blockers[i].OpenedOn = date
+			}
+		}
+		if _, err := s.onSaveBlockers(repoName, date, payload.Blockers); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	blockers, err := s.onLoadBlockers(repoName, date)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Only the report on screen picks up the change: same repo, same day.
+	s.mu.Lock()
+	current := s.state.Repo == repoName && gitdiff.ISODate(s.state.Date) == gitdiff.ISODate(date)
+	s.mu.Unlock()
+	if current {
+		// SetBlockers re-renders the report, so it is read afterwards.
+		s.SetBlockers(blockers)
+		if r.Method == http.MethodPost && s.onSave != nil {
+			s.mu.Lock()
+			report := s.state.Report
+			s.mu.Unlock()
+			_ = s.onSave(date, s.GetTasks(), report)
+		}
+	}
+
+	if blockers == nil {
+		blockers = []gitdiff.Blocker{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(blockers)
+}